@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/user"
@@ -45,11 +46,80 @@ func statFlags() []cli.Flag {
 			Value: -1,
 			Usage: "Number of weeks to compute",
 		},
+		&cli.StringFlag{
+			Name:  "since",
+			Value: "",
+			Usage: "First day to compute (YYYY-MM-DD, ytd, last-quarter or last-sprint:<n>[y|m|w|d]), instead of weeks and delta",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Value: "",
+			Usage: "Last day to compute (YYYY-MM-DD), instead of weeks and delta",
+		},
 		&cli.BoolFlag{
 			Name:  "merge",
 			Value: false,
 			Usage: "Merge all scanned repository",
 		},
+		&cli.IntFlag{
+			Name:  "jobs",
+			Value: 0,
+			Usage: "Number of repositories scanned concurrently (default: one per CPU)",
+		},
+		&cli.StringFlag{
+			Name:  "backend",
+			Value: stats.BackendGoGit,
+			Usage: "History reader: go-git, or git to run the git command line (falls back to go-git when git is not installed)",
+		},
+		&cli.BoolFlag{
+			Name:  "all-branches",
+			Value: false,
+			Usage: "Scan every local branch, not only HEAD",
+		},
+		&cli.BoolFlag{
+			Name:  "remote-branches",
+			Value: false,
+			Usage: "Scan every remote-tracking branch, not only HEAD",
+		},
+		&cli.StringSliceFlag{
+			Name:  "ref",
+			Usage: "Scan this ref (branch, tag or commit) instead of HEAD; repeatable",
+		},
+		&cli.BoolFlag{
+			Name:  "co-authors",
+			Value: false,
+			Usage: "Also credit commits to their Co-authored-by trailers",
+		},
+		&cli.StringFlag{
+			Name:  "attribute-by",
+			Value: stats.AttributeAuthor,
+			Usage: "Identity and date commits are attributed to: author or committer",
+		},
+		&cli.StringFlag{
+			Name:  "timezone",
+			Value: stats.TimezoneCommit,
+			Usage: "Time zone commits are bucketed in by day, hour and weekday: commit, local, utc or an IANA name",
+		},
+		&cli.StringFlag{
+			Name:  "merges",
+			Value: stats.MergesInclude,
+			Usage: "Merge commits policy: include, exclude or only",
+		},
+		&cli.IntFlag{
+			Name:  "top-files",
+			Value: stats.DefaultTopFiles,
+			Usage: "Number of most changed files reported (negative for every file)",
+		},
+		&cli.IntFlag{
+			Name:  "inactive-days",
+			Value: stats.DefaultInactiveDays,
+			Usage: "Days without a commit after which a contributor is inactive",
+		},
+		&cli.BoolFlag{
+			Name:  "surviving-lines",
+			Value: false,
+			Usage: "Also blame the HEAD tree to count who wrote the lines that exist today",
+		},
 		&cli.BoolFlag{
 			Name:  "count-all",
 			Value: false,
@@ -131,6 +201,125 @@ func commands() []*cli.Command {
 				},
 			),
 		},
+		{
+			Name:  "hotspots",
+			Usage: "List the most changed files",
+			Action: func(c *cli.Context) error {
+				return runHotspots(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "sort",
+					Value: stats.FileSortCommits,
+					Usage: "Order of the files: commits, churn, additions, deletions, authors or lastModified",
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Value: "",
+					Usage: "Only list the files under this directory",
+				},
+			),
+		},
+		{
+			Name:  "ownership",
+			Usage: "Show who owns each directory and its bus factor",
+			Action: func(c *cli.Context) error {
+				return runOwnership(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.IntFlag{
+					Name:  "depth",
+					Value: stats.DefaultOwnershipDepth,
+					Usage: "Number of directory levels reported",
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Value: "",
+					Usage: "Only report the directories under this directory",
+				},
+				&cli.BoolFlag{
+					Name:  "inactive",
+					Value: false,
+					Usage: "Only report the directories whose top owner is inactive",
+				},
+			),
+		},
+		{
+			Name:  "compare",
+			Usage: "Compare the scan window with the previous one or the same window a year earlier",
+			Action: func(c *cli.Context) error {
+				return runCompare(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "against",
+					Value: stats.ComparePrevious,
+					Usage: "Compared window: previous (the same number of days, just before) or yoy (one year earlier)",
+				},
+			),
+		},
+		{
+			Name:  "export",
+			Usage: "Export a table of the statistics (calendar, contributors, languages, commitTypes, repositories or punchcard)",
+			Action: func(c *cli.Context) error {
+				return runExport(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "format",
+					Value: stats.ExportCSV,
+					Usage: "Export format: csv (a table), ics (the days with commits, as calendar events) or sqlite (the commits, their files and identities, to --out)",
+				},
+				&cli.StringFlag{
+					Name:  "table",
+					Value: stats.TableContributors,
+					Usage: "Exported table, as csv: calendar, contributors, languages, commitTypes, repositories or punchcard",
+				},
+				&cli.BoolFlag{
+					Name:  "per-contributor",
+					Value: false,
+					Usage: "As ics, an event per contributor and day instead of one per day",
+				},
+				&cli.StringFlag{
+					Name:  "out",
+					Value: "",
+					Usage: "File written (default: the standard output)",
+				},
+			),
+		},
+		{
+			Name:  "report",
+			Usage: "Write the web interface as a self-contained HTML report, viewable offline",
+			Action: func(c *cli.Context) error {
+				return runReport(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "out",
+					Value: "report.html",
+					Usage: "File written",
+				},
+			),
+		},
+		{
+			Name:  "svg",
+			Usage: "Render a chart as an SVG image: heatmap, punchcard or languages",
+			Action: func(c *cli.Context) error {
+				return runSVG(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "chart",
+					Value: stats.ChartHeatmap,
+					Usage: "Chart rendered: heatmap, punchcard or languages",
+				},
+				&cli.StringFlag{
+					Name:  "out",
+					Value: "",
+					Usage: "File written (default: the standard output)",
+				},
+			),
+		},
 		{
 			Name:    "stat",
 			Aliases: []string{"s"},
@@ -138,7 +327,27 @@ func commands() []*cli.Command {
 			Action: func(c *cli.Context) error {
 				return runStat(c)
 			},
-			Flags: statFlags(),
+			Flags: append(statFlags(),
+				&cli.StringFlag{
+					Name:  "format",
+					Value: stats.FormatText,
+					Usage: "Output format: text (the heatmap), markdown (a summary, as the summary command), json (the statistics of /api/stats) or ndjson (a JSON line per repository, unless merged)",
+				},
+			),
+		},
+		{
+			Name:  "summary",
+			Usage: "Print a Markdown summary for pull requests and release notes",
+			Action: func(c *cli.Context) error {
+				return runSummary(c)
+			},
+			Flags: append(append(statFlags(), patternFlags()...),
+				&cli.StringFlag{
+					Name:  "out",
+					Value: "",
+					Usage: "File written (default: the standard output)",
+				},
+			),
 		},
 	}
 }
@@ -243,6 +452,62 @@ func buildLaunchOptions(c *cli.Context, cfg *stats.Config, console bool) (stats.
 		merge = *cfg.Merge
 	}
 
+	jobs := c.Int("jobs")
+	if !c.IsSet("jobs") && cfg.Jobs != nil {
+		jobs = *cfg.Jobs
+	}
+
+	backend, err := stats.ParseBackend(strFlag(c, "backend", cfg.Backend))
+	if err != nil {
+		return stats.LaunchOptions{}, err
+	}
+
+	allBranches := c.Bool("all-branches")
+	if !c.IsSet("all-branches") && cfg.AllBranches != nil {
+		allBranches = *cfg.AllBranches
+	}
+	remoteBranches := c.Bool("remote-branches")
+	if !c.IsSet("remote-branches") && cfg.RemoteBranches != nil {
+		remoteBranches = *cfg.RemoteBranches
+	}
+	refs := c.StringSlice("ref")
+	if !c.IsSet("ref") && len(cfg.Refs) > 0 {
+		refs = cfg.Refs
+	}
+
+	coAuthors := c.Bool("co-authors")
+	if !c.IsSet("co-authors") && cfg.CoAuthors != nil {
+		coAuthors = *cfg.CoAuthors
+	}
+
+	attributeBy, err := stats.ParseAttribution(strFlag(c, "attribute-by", cfg.AttributeBy))
+	if err != nil {
+		return stats.LaunchOptions{}, err
+	}
+	if err := stats.CheckCoAuthors(attributeBy, coAuthors); err != nil {
+		return stats.LaunchOptions{}, err
+	}
+	merges, err := stats.ParseMerges(strFlag(c, "merges", cfg.Merges))
+	if err != nil {
+		return stats.LaunchOptions{}, err
+	}
+	timezone, err := stats.ParseTimezone(strFlag(c, "timezone", cfg.Timezone))
+	if err != nil {
+		return stats.LaunchOptions{}, err
+	}
+	topFiles := c.Int("top-files")
+	if !c.IsSet("top-files") && cfg.TopFiles != nil {
+		topFiles = *cfg.TopFiles
+	}
+	survivingLines := c.Bool("surviving-lines")
+	if !c.IsSet("surviving-lines") && cfg.SurvivingLines != nil {
+		survivingLines = *cfg.SurvivingLines
+	}
+	inactiveDays := c.Int("inactive-days")
+	if !c.IsSet("inactive-days") && cfg.InactiveDays != nil {
+		inactiveDays = *cfg.InactiveDays
+	}
+
 	include := c.StringSlice("file-include-pattern")
 	if !c.IsSet("file-include-pattern") && len(cfg.IncludePatterns) > 0 {
 		include = cfg.IncludePatterns
@@ -257,25 +522,255 @@ func buildLaunchOptions(c *cli.Context, cfg *stats.Config, console bool) (stats.
 		DurationInWeeks:  durationInWeeks,
 		Folders:          folders,
 		Merge:            merge,
+		Jobs:             jobs,
+		Backend:          backend,
+		AllBranches:      allBranches,
+		RemoteBranches:   remoteBranches,
+		Refs:             refs,
+		CoAuthors:        coAuthors,
+		AttributeBy:      attributeBy,
+		Merges:           merges,
+		Timezone:         timezone,
+		TopFiles:         topFiles,
+		InactiveDays:     inactiveDays,
+		SurvivingLines:   survivingLines,
 		Delta:            strFlag(c, "delta", cfg.Delta),
+		Since:            strFlag(c, "since", cfg.Since),
+		Until:            strFlag(c, "until", cfg.Until),
 		PatternToExclude: exclude,
 		PatternToInclude: include,
 	}, nil
 }
 
 func runStat(c *cli.Context) error {
+	format, err := stats.ParseFormat(c.String("format"))
+	if err != nil {
+		return err
+	}
 	cfg, err := stats.LoadConfig(c.String("config"))
 	if err != nil {
 		return err
 	}
-	opts, err := buildLaunchOptions(c, cfg, true)
+	// Only the heatmap has to fit in the terminal.
+	opts, err := buildLaunchOptions(c, cfg, format == stats.FormatText)
 	if err != nil {
 		return err
 	}
-	stats.Launch(opts)
+	if format == stats.FormatText {
+		stats.Launch(opts)
+		return nil
+	}
+	opts.Dashboard = true
+	results := stats.Launch(opts)
+	switch format {
+	case stats.FormatJSON:
+		return stats.WriteJSON(os.Stdout, results)
+	case stats.FormatNDJSON:
+		return stats.WriteNDJSON(os.Stdout, results)
+	default:
+		return stats.WriteMarkdown(os.Stdout, stats.Aggregate(results))
+	}
+}
+
+func runSummary(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+
+	out, err := openOut(c.String("out"))
+	if err != nil {
+		return err
+	}
+	err = stats.WriteMarkdown(out, agg)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func runHotspots(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	by, err := stats.ParseFileSort(c.String("sort"))
+	if err != nil {
+		return err
+	}
+	// Every file is kept, then filtered, sorted and cut to --top-files.
+	limit := opts.TopFiles
+	opts.TopFiles = -1
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+	files := stats.FilterFiles(agg.Files, c.String("prefix"))
+	stats.SortFiles(files, by)
+	if limit >= 0 && len(files) > limit {
+		files = files[:limit]
+	}
+	stats.PrintFiles(files)
+	return nil
+}
+
+func runOwnership(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	if c.Int("depth") < 1 {
+		return errors.New("--depth must be at least 1")
+	}
+	opts.OwnershipDepth = c.Int("depth")
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+	stats.PrintOwnership(stats.FilterOwnership(agg.Ownership, c.String("prefix"), c.Bool("inactive")))
+	return nil
+}
+
+func runCompare(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	mode, err := stats.ParseCompare(c.String("against"))
+	if err != nil {
+		return err
+	}
+	if mode == stats.CompareNone {
+		return errors.New("--against must be previous or yoy")
+	}
+	prevOpts, err := stats.ComparedOptions(opts, mode)
+	if err != nil {
+		return err
+	}
+	opts.Dashboard = true
+	prevOpts.Dashboard = true
+	current := stats.Aggregate(stats.Launch(opts))
+	previous := stats.Aggregate(stats.Launch(prevOpts))
+	stats.PrintComparison(current, stats.Compare(mode, current, previous))
 	return nil
 }
 
+func runExport(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	format, err := stats.ParseExportFormat(c.String("format"))
+	if err != nil {
+		return err
+	}
+	table, err := stats.ParseExportTable(c.String("table"))
+	if err != nil {
+		return err
+	}
+	perContributor := c.Bool("per-contributor")
+	if perContributor {
+		opts = stats.PerContributorEvents(opts)
+	}
+	if format == stats.ExportSQLite {
+		if c.String("out") == "" {
+			return errors.New("--out is required with --format sqlite")
+		}
+		opts.Records = true
+	}
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+	if format == stats.ExportSQLite {
+		return stats.WriteSQLite(c.String("out"), agg)
+	}
+
+	out, err := openOut(c.String("out"))
+	if err != nil {
+		return err
+	}
+	if format == stats.ExportICS {
+		err = stats.WriteICS(out, agg, perContributor, time.Now())
+	} else {
+		err = stats.WriteCSV(out, agg, table)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func runReport(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+
+	out, err := openOut(c.String("out"))
+	if err != nil {
+		return err
+	}
+	err = stats.WriteReport(out, agg, time.Now())
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Report written to %s\n", c.String("out"))
+	return nil
+}
+
+func runSVG(c *cli.Context) error {
+	cfg, err := stats.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	opts, err := buildLaunchOptions(c, cfg, false)
+	if err != nil {
+		return err
+	}
+	chart, err := stats.ParseChart(c.String("chart"))
+	if err != nil {
+		return err
+	}
+	opts.Dashboard = true
+	agg := stats.Aggregate(stats.Launch(opts))
+
+	out, err := openOut(c.String("out"))
+	if err != nil {
+		return err
+	}
+	err = stats.WriteSVG(out, agg, chart)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
 func runDashboard(c *cli.Context) error {
 	cfg, err := stats.LoadConfig(c.String("config"))
 	if err != nil {
@@ -310,9 +805,24 @@ func runWeb(c *cli.Context) error {
 		cacheFile = defaultCacheFile()
 	}
 
-	return stats.Serve(opts, strFlag(c, "addr", cfg.Web.Addr), ttl, cacheFile)
+	return stats.Serve(opts, strFlag(c, "addr", cfg.Web.Addr), ttl, cacheFile, cfg.Web.MetricSets)
+}
+
+// openOut opens the --out file of a command, created or truncated, or the
+// standard output when path is empty. Closing the standard output does
+// nothing; closing the file reports whether it was fully written.
+func openOut(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
 }
 
+// nopCloser is a writer whose Close does nothing.
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
 // defaultCacheFile returns the default web cache path, in the user's home
 // directory, falling back to the current directory if the home is unknown.
 func defaultCacheFile() string {
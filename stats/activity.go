@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// commitSpan is the first and last commit of an author.
+type commitSpan struct {
+	First, Last time.Time
+}
+
+// add widens the span to include when.
+func (s commitSpan) add(when time.Time) commitSpan {
+	if s.First.IsZero() || when.Before(s.First) {
+		s.First = when
+	}
+	if when.After(s.Last) {
+		s.Last = when
+	}
+	return s
+}
+
+// ContributorActivity is when a contributor committed first and last.
+type ContributorActivity struct {
+	Author      string    `json:"author"`
+	Identities  []string  `json:"identities"`
+	FirstCommit time.Time `json:"firstCommit"`
+	LastCommit  time.Time `json:"lastCommit"`
+}
+
+// fillSpans records the first and last commit of every credited author over
+// the whole history, up to the end of the window: the window does not apply,
+// but the user filter, the merges policy and the attribution do.
+func fillSpans(r *StatsResult, commits []*commitFact, mailmap *Mailmap, users []string) {
+	for _, c := range commits {
+		if !keepCommit(c, r.Options.Merges) {
+			continue
+		}
+		when := c.when(r.Options.AttributeBy)
+		if when.After(r.EndOfScan) {
+			continue
+		}
+		for _, id := range filterUsers(creditedIdentities(c, mailmap, r.Options.AttributeBy, r.Options.CoAuthors), users) {
+			authorKey := id.Name + authorIDSep + id.Email
+			r.Spans[authorKey] = r.Spans[authorKey].add(when)
+		}
+	}
+}
+
+// mergeSpans widens the spans of dst with the ones of src.
+func mergeSpans(dst, src map[string]commitSpan) {
+	for author, s := range src {
+		dst[author] = dst[author].add(s.First).add(s.Last)
+	}
+}
+
+// buildActivity groups the spans of the identities per contributor, like
+// mergeAuthorAliases, and returns them by alias group key with the grouped
+// contributors (names and identities).
+func buildActivity(spans map[string]commitSpan) (map[string]commitSpan, []Contributor) {
+	grouped := make(map[string]commitSpan)
+	editions := make(map[string][4]int, len(spans))
+	for author, s := range spans {
+		key := aliasGroupKey(splitAuthorKey(author))
+		grouped[key] = grouped[key].add(s.First).add(s.Last)
+		editions[author] = [4]int{}
+	}
+	return grouped, mergeAuthorAliases(editions, nil)
+}
+
+// newContributors lists the contributors whose first commit ever falls in the
+// window, the earliest first.
+func newContributors(contributors []Contributor, begin time.Time) []ContributorActivity {
+	var activities []ContributorActivity
+	for _, c := range contributors {
+		if !c.FirstCommit.Before(begin) {
+			activities = append(activities, ContributorActivity{Author: c.Author, Identities: c.Identities, FirstCommit: c.FirstCommit, LastCommit: c.LastCommit})
+		}
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].FirstCommit.Before(activities[j].FirstCommit)
+	})
+	return activities
+}
+
+// inactiveContributors lists the contributors of the whole history without a
+// commit in the last inactiveDays days of the window (all of them when
+// inactiveDays is negative), the most recently active first. names overrides
+// the display name of the ones contributing in the window, so both lists
+// agree.
+func inactiveContributors(grouped map[string]commitSpan, everyone []Contributor, names map[string]string, inactiveDays int, end time.Time) []ContributorActivity {
+	cutoff := end.AddDate(0, 0, -inactiveDays)
+	var activities []ContributorActivity
+	for _, c := range everyone {
+		s := grouped[c.key]
+		if inactiveDays >= 0 && !s.Last.Before(cutoff) {
+			continue
+		}
+		author := c.Author
+		if name, ok := names[c.key]; ok {
+			author = name
+		}
+		activities = append(activities, ContributorActivity{Author: author, Identities: c.Identities, FirstCommit: s.First, LastCommit: s.Last})
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		if !activities[i].LastCommit.Equal(activities[j].LastCommit) {
+			return activities[i].LastCommit.After(activities[j].LastCommit)
+		}
+		return activities[i].Author < activities[j].Author
+	})
+	return activities
+}
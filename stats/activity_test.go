@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLaunchContributorActivity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	now := time.Now()
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", now.AddDate(0, 0, -200))
+	commitFile(t, path, "b.go", "b\n", "feat: b", "bob", now.AddDate(0, 0, -100))
+	commitFile(t, path, "b.go", "b\nb\n", "fix: b", "bob", now.AddDate(0, 0, -5))
+	commitFile(t, path, "c.go", "c\n", "feat: c", "carol", now.AddDate(0, 0, -3))
+	// Commits after the window do not count.
+	commitFile(t, path, "a.go", "a\na\n", "fix: a", "alice", now.AddDate(0, 0, 2))
+
+	opts := LaunchOptions{Since: now.AddDate(0, 0, -27).Format(dateLayout), Until: now.Format(dateLayout), Folders: []string{path}, Dashboard: true}
+	agg := Aggregate(Launch(opts))
+
+	spans := map[string][2]time.Time{}
+	for _, c := range agg.Contributors {
+		spans[c.Author] = [2]time.Time{c.FirstCommit, c.LastCommit}
+	}
+	if got := spans["bob"]; !sameDay(got[0], now.AddDate(0, 0, -100)) || !sameDay(got[1], now.AddDate(0, 0, -5)) {
+		t.Errorf("bob spans %v, want his first and last commits", got)
+	}
+	if len(agg.NewContributors) != 1 || agg.NewContributors[0].Author != "carol" {
+		t.Errorf("new contributors %+v, want carol", agg.NewContributors)
+	}
+	if len(agg.InactiveContributors) != 1 || agg.InactiveContributors[0].Author != "alice" ||
+		!sameDay(agg.InactiveContributors[0].LastCommit, now.AddDate(0, 0, -200)) {
+		t.Errorf("inactive contributors %+v, want alice", agg.InactiveContributors)
+	}
+
+	opts.InactiveDays = 4
+	agg = Aggregate(Launch(opts))
+	if len(agg.InactiveContributors) != 2 || agg.InactiveContributors[0].Author != "bob" {
+		t.Errorf("inactive contributors %+v, want bob then alice", agg.InactiveContributors)
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format(dateLayout) == b.Format(dateLayout)
+}
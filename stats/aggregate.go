@@ -12,15 +12,30 @@ type Contributor struct {
 	Additions int    `json:"additions"`
 	Deletions int    `json:"deletions"`
 	Total     int    `json:"total"`
+	Commits   int    `json:"commits"`
+	// CoAuthored is how many of Commits credit this contributor through a
+	// Co-authored-by trailer rather than as the author.
+	CoAuthored int `json:"coAuthored"`
 	// Identities lists the names and emails merged into this contributor, so a
 	// client can filter on exactly this person (all their aliases).
 	Identities []string `json:"identities"`
+	// UTCOffsets is how many commits they made from each UTC offset, the most
+	// used first.
+	UTCOffsets []OffsetCount `json:"utcOffsets"`
+	// FirstCommit and LastCommit span their commits over the whole history,
+	// up to the end of the window.
+	FirstCommit time.Time `json:"firstCommit"`
+	LastCommit  time.Time `json:"lastCommit"`
+
+	key string // alias group key
 }
 
-// RepositoryStat is the commit count of a single scanned repository.
+// RepositoryStat is the activity of a single scanned repository.
 type RepositoryStat struct {
-	Folder  string `json:"folder"`
-	Commits int    `json:"commits"`
+	Folder    string `json:"folder"`
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
 }
 
 // Language is the amount of changes attributed to a programming language (or
@@ -55,6 +70,8 @@ type AggregatedStats struct {
 	EndOfScan        time.Time         `json:"endOfScan"`
 	DurationInDays   int               `json:"durationInDays"`
 	TotalCommits     int               `json:"totalCommits"`
+	MergeCommits     int               `json:"mergeCommits"`
+	NonMergeCommits  int               `json:"nonMergeCommits"`
 	AnalyzedRepos    int               `json:"analyzedRepos"`
 	Errors           int               `json:"errors"`
 	CommitsByHour    [24]int           `json:"commitsByHour"`    // index 0..23
@@ -65,6 +82,29 @@ type AggregatedStats struct {
 	Languages        []Language        `json:"languages"`
 	CommitTypes      []CommitTypeCount `json:"commitTypes"`
 	Calendar         []DayCount        `json:"calendar"`
+	// Files lists the most changed files (by commits touching them), up to
+	// the TopFiles launch option.
+	Files []FileStat `json:"files"`
+	// Ownership is who owns each directory, when the OwnershipDepth launch
+	// option asks for it.
+	Ownership []DirectoryOwnership `json:"ownership,omitempty"`
+	// SurvivingLines is who wrote the code of the HEAD trees, when the
+	// SurvivingLines launch option asks for it.
+	SurvivingLines *SurvivingLines `json:"survivingLines,omitempty"`
+	// Series is the weekly activity of the top contributors, when the Series
+	// launch option asks for it.
+	Series []ContributorSeries `json:"series,omitempty"`
+	// ContributorDays is the daily activity of every contributor, when the
+	// ContributorDays launch option asks for it.
+	ContributorDays []ContributorDays `json:"contributorDays,omitempty"`
+	// Commits are the counted commits, oldest first, when the Records launch
+	// option asks for them.
+	Commits []CommitRecord `json:"commits,omitempty"`
+	// NewContributors made their first commit ever in the window.
+	NewContributors []ContributorActivity `json:"newContributors"`
+	// InactiveContributors have not committed in the last InactiveDays days of
+	// the window.
+	InactiveContributors []ContributorActivity `json:"inactiveContributors"`
 
 	// merged keeps the underlying merged result so the terminal dashboard can
 	// reuse the same commit map for its heatmap. Not serialized.
@@ -96,9 +136,15 @@ func Aggregate(results []*StatsResult) AggregatedStats {
 		DayEditions:    make(map[int][2]int),
 	}
 
-	editions := make(map[string][2]int)     // author -> [additions, deletions]
-	langEditions := make(map[string][2]int) // language -> [additions, deletions]
-	commitTypes := make(map[string]int)     // conventional type -> count
+	editions := make(map[string][4]int)          // author -> [additions, deletions, commits, co-authored]
+	offsets := make(map[string]map[string]int)   // author -> UTC offset -> commits
+	langEditions := make(map[string][2]int)      // language -> [additions, deletions]
+	commitTypes := make(map[string]int)          // conventional type -> count
+	files := make(map[fileKey]*fileEditions)     // file -> activity
+	spans := make(map[string]commitSpan)         // author -> first and last commit
+	surviving := make(map[string]map[string]int) // author -> language -> lines
+	series := make(map[string]map[int][3]int)    // author -> day -> [commits, additions, deletions]
+	var records []CommitRecord
 
 	for _, l := range results {
 		if l.Error != nil {
@@ -113,16 +159,22 @@ func Aggregate(results []*StatsResult) AggregatedStats {
 			commitsByRepo += commit
 			merged.Commits[i] += commit
 		}
+		additionsByRepo, deletionsByRepo := 0, 0
 		for i, de := range l.DayEditions {
 			m := merged.DayEditions[i]
 			m[0] += de[0]
 			m[1] += de[1]
 			merged.DayEditions[i] = m
+			additionsByRepo += de[0]
+			deletionsByRepo += de[1]
 		}
+		agg.MergeCommits += l.MergeCommits
 		if commitsByRepo > 0 {
 			agg.Repositories = append(agg.Repositories, RepositoryStat{
-				Folder:  l.Folder,
-				Commits: commitsByRepo,
+				Folder:    l.Folder,
+				Commits:   commitsByRepo,
+				Additions: additionsByRepo,
+				Deletions: deletionsByRepo,
 			})
 		}
 
@@ -141,8 +193,11 @@ func Aggregate(results []*StatsResult) AggregatedStats {
 			e := editions[author]
 			e[0] += c["additions"]
 			e[1] += c["deletions"]
+			e[2] += c["commits"]
+			e[3] += c["coauthored"]
 			editions[author] = e
 		}
+		mergeEditions(offsets, l.Offsets)
 		for lang, c := range l.LanguageEditions {
 			e := langEditions[lang]
 			e[0] += c["additions"]
@@ -152,10 +207,35 @@ func Aggregate(results []*StatsResult) AggregatedStats {
 		for t, n := range l.CommitTypes {
 			commitTypes[t] += n
 		}
+		mergeFileEditions(files, l.Files)
+		mergeSpans(spans, l.Spans)
+		mergeEditions(surviving, l.SurvivingLines)
+		mergeSeries(series, l.Series)
+		records = append(records, l.Records...)
 	}
 
+	agg.NonMergeCommits = agg.TotalCommits - agg.MergeCommits
+
 	// Collapse the per-identity editions into one entry per person.
-	agg.Contributors = mergeAuthorAliases(editions)
+	agg.Contributors = mergeAuthorAliases(editions, offsets)
+
+	grouped, everyone := buildActivity(spans)
+	names := make(map[string]string, len(agg.Contributors))
+	lastCommits := make(map[string]time.Time, len(grouped))
+	for i, c := range agg.Contributors {
+		names[c.key] = c.Author
+		agg.Contributors[i].FirstCommit = grouped[c.key].First
+		agg.Contributors[i].LastCommit = grouped[c.key].Last
+	}
+	for key, s := range grouped {
+		lastCommits[key] = s.Last
+	}
+	inactiveDays := first.Options.InactiveDays
+	if inactiveDays == 0 {
+		inactiveDays = DefaultInactiveDays
+	}
+	agg.NewContributors = newContributors(agg.Contributors, first.BeginOfScan)
+	agg.InactiveContributors = inactiveContributors(grouped, everyone, names, inactiveDays, first.EndOfScan)
 
 	for lang, e := range langEditions {
 		agg.Languages = append(agg.Languages, Language{
@@ -165,30 +245,64 @@ func Aggregate(results []*StatsResult) AggregatedStats {
 			Total:     e[0] + e[1],
 		})
 	}
+	// Ties are broken by name, so the output is stable between runs.
 	sort.Slice(agg.Languages, func(i, j int) bool {
-		return agg.Languages[i].Total > agg.Languages[j].Total
+		if agg.Languages[i].Total != agg.Languages[j].Total {
+			return agg.Languages[i].Total > agg.Languages[j].Total
+		}
+		return agg.Languages[i].Name < agg.Languages[j].Name
 	})
 
 	for t, n := range commitTypes {
 		agg.CommitTypes = append(agg.CommitTypes, CommitTypeCount{Type: t, Count: n})
 	}
 	sort.Slice(agg.CommitTypes, func(i, j int) bool {
-		return agg.CommitTypes[i].Count > agg.CommitTypes[j].Count
+		if agg.CommitTypes[i].Count != agg.CommitTypes[j].Count {
+			return agg.CommitTypes[i].Count > agg.CommitTypes[j].Count
+		}
+		return agg.CommitTypes[i].Type < agg.CommitTypes[j].Type
 	})
 
+	top := first.Options.TopFiles
+	if top == 0 {
+		top = DefaultTopFiles
+	}
+	agg.Files = fileStats(files)
+	SortFiles(agg.Files, FileSortCommits)
+	agg.Files = topFiles(agg.Files, top)
+
+	if depth := first.Options.OwnershipDepth; depth != 0 {
+		agg.Ownership = buildOwnership(files, names, lastCommits, depth, inactiveDays, first.EndOfScan)
+	}
+
+	if first.Options.SurvivingLines {
+		agg.SurvivingLines = buildSurvivingLines(surviving)
+	}
+
+	if n := first.Options.Series; n > 0 {
+		agg.Series = buildSeries(merged, series, agg.Contributors, n)
+	}
+	if first.Options.ContributorDays {
+		agg.ContributorDays = buildContributorDays(merged, series, agg.Contributors)
+	}
+
+	if first.Options.Records {
+		agg.Commits = buildRecords(records, agg.Contributors)
+	}
+
 	agg.Calendar = buildCalendar(merged)
 	agg.merged = merged
 	return agg
 }
 
 // buildCalendar turns the merged commit map into a chronological list of daily
-// counts, using the same index arithmetic as the terminal heatmap so both
-// views show identical values.
+// counts, indexed like the terminal heatmap so both views show identical
+// values.
 func buildCalendar(r *StatsResult) []DayCount {
 	end := getEndOfDay(r.EndOfScan)
 	var days []DayCount
 	for d := r.BeginOfScan; d.Before(end); d = d.AddDate(0, 0, 1) {
-		idx := int(end.Sub(d).Hours()/24) + 8
+		idx := r.dayIndex(d)
 		de := r.DayEditions[idx]
 		days = append(days, DayCount{
 			Date:      d.Format("2006-01-02"),
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Identities a commit can be attributed to.
+const (
+	AttributeAuthor    = "author"    // who wrote the change (the default)
+	AttributeCommitter = "committer" // who applied it, e.g. when rebasing or cherry-picking
+)
+
+// ParseAttribution validates an attribution mode; an empty mode selects the
+// default.
+func ParseAttribution(mode string) (string, error) {
+	switch mode {
+	case "":
+		return AttributeAuthor, nil
+	case AttributeAuthor, AttributeCommitter:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown attribution %q, use %s or %s", mode, AttributeAuthor, AttributeCommitter)
+	}
+}
+
+// CheckCoAuthors rejects crediting co-authors when attributing by committer:
+// the Co-authored-by trailers name the co-authors of the author.
+func CheckCoAuthors(attributeBy string, coAuthors bool) error {
+	if coAuthors && attributeBy == AttributeCommitter {
+		return fmt.Errorf("co-authors are only credited when attributing by %s, not by %s", AttributeAuthor, AttributeCommitter)
+	}
+	return nil
+}
+
+// identity is a person as recorded in a commit: a name and an email.
+type identity struct {
+	Name  string
+	Email string
+
+	coAuthor bool // credited through a Co-authored-by trailer
+}
+
+// when returns the moment a commit is placed at for the attribution mode.
+func (c *commitFact) when(attributeBy string) time.Time {
+	if attributeBy == AttributeCommitter {
+		return c.CommitterWhen
+	}
+	return c.AuthorWhen
+}
+
+// creditedIdentities returns the mailmap-resolved identities a commit is
+// credited to: its author followed, when coAuthored is set, by its co-authors;
+// or its committer alone when attributing by committer. An identity is
+// credited once.
+func creditedIdentities(c *commitFact, mailmap *Mailmap, attributeBy string, coAuthored bool) []identity {
+	if attributeBy == AttributeCommitter {
+		name, email := mailmap.Resolve(c.CommitterName, c.CommitterEmail)
+		return []identity{{Name: name, Email: email}}
+	}
+	name, email := mailmap.Resolve(c.AuthorName, c.AuthorEmail)
+	credited := []identity{{Name: name, Email: email}}
+	if !coAuthored {
+		return credited
+	}
+	seen := map[string]bool{strings.ToLower(email): true}
+	for _, co := range c.CoAuthors {
+		name, email := mailmap.Resolve(co.Name, co.Email)
+		key := strings.ToLower(email)
+		if email == "" {
+			key = "name:" + strings.ToLower(name)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		credited = append(credited, identity{Name: name, Email: email, coAuthor: true})
+	}
+	return credited
+}
+
+// filterUsers keeps the credited identities that are one of the users of a
+// user filter; all of them without a filter.
+func filterUsers(credited []identity, users []string) []identity {
+	if users == nil {
+		return credited
+	}
+	matching := credited[:0]
+	for _, id := range credited {
+		if matchesUser(users, id.Name, id.Email) {
+			matching = append(matching, id)
+		}
+	}
+	return matching
+}
+
+// matchesUser reports whether an identity is one of the users of a user
+// filter: a token with an "@" matches the email, any other the name.
+func matchesUser(users []string, name, email string) bool {
+	for _, u := range users {
+		if strings.Contains(u, "@") && email == u {
+			return true
+		} else if name == u {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseAttribution(t *testing.T) {
+	for mode, want := range map[string]string{"": AttributeAuthor, "author": AttributeAuthor, "committer": AttributeCommitter} {
+		got, err := ParseAttribution(mode)
+		if err != nil || got != want {
+			t.Errorf("ParseAttribution(%q) = (%q, %v), want %q", mode, got, err, want)
+		}
+	}
+	if _, err := ParseAttribution("reviewer"); err == nil {
+		t.Error("an unknown attribution should be an error")
+	}
+	if CheckCoAuthors(AttributeAuthor, true) != nil || CheckCoAuthors(AttributeCommitter, false) != nil {
+		t.Error("co-authors by author, and the committer alone, should be accepted")
+	}
+	if CheckCoAuthors(AttributeCommitter, true) == nil {
+		t.Error("co-authors by committer should be an error")
+	}
+}
+
+func TestMatchesUser(t *testing.T) {
+	users := []string{"Alice", "bob@e"}
+	if !matchesUser(users, "Alice", "a@e") || !matchesUser(users, "Robert", "bob@e") {
+		t.Error("name and email tokens should match")
+	}
+	if matchesUser(users, "Bob", "other@e") || matchesUser(users, "alice", "a@e") {
+		t.Error("other identities should not match")
+	}
+}
+
+func TestLaunchAttributeByCommitter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	now := time.Now()
+	// Written three weeks ago by Alice, cherry-picked today by Rita.
+	author := &object.Signature{Name: "Alice", Email: "alice@e", When: now.AddDate(0, 0, -21).Add(-time.Hour)}
+	committer := &object.Signature{Name: "Rita", Email: "rita@e", When: now.Add(-time.Hour)}
+	commitAs(t, path, "a.go", "a\n", "fix: picked", author, committer)
+
+	launch := func(attributeBy, user string) AggregatedStats {
+		opts := LaunchOptions{DurationInWeeks: 8, Folders: []string{path}, Dashboard: true, AttributeBy: attributeBy}
+		if user != "" {
+			opts.User = &user
+		}
+		return Aggregate(Launch(opts))
+	}
+	lastActiveDay := func(agg AggregatedStats) string {
+		day := ""
+		for _, d := range agg.Calendar {
+			if d.Count > 0 {
+				day = d.Date
+			}
+		}
+		return day
+	}
+
+	byAuthor := launch(AttributeAuthor, "")
+	byCommitter := launch(AttributeCommitter, "")
+	if len(byAuthor.Contributors) != 1 || byAuthor.Contributors[0].Author != "Alice" {
+		t.Errorf("author mode credits %+v, want Alice", byAuthor.Contributors)
+	}
+	if len(byCommitter.Contributors) != 1 || byCommitter.Contributors[0].Author != "Rita" {
+		t.Errorf("committer mode credits %+v, want Rita", byCommitter.Contributors)
+	}
+	if want := author.When.Format("2006-01-02"); lastActiveDay(byAuthor) != want {
+		t.Errorf("author mode places the commit on %s, want %s", lastActiveDay(byAuthor), want)
+	}
+	if want := committer.When.Format("2006-01-02"); lastActiveDay(byCommitter) != want {
+		t.Errorf("committer mode places the commit on %s, want %s", lastActiveDay(byCommitter), want)
+	}
+	if agg := launch(AttributeCommitter, "alice@e"); agg.TotalCommits != 0 {
+		t.Error("committer mode should not match the author in the user filter")
+	}
+	if agg := launch(AttributeCommitter, "rita@e"); agg.TotalCommits != 1 {
+		t.Error("committer mode should match the committer in the user filter")
+	}
+}
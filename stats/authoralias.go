@@ -28,12 +28,16 @@ func splitAuthorKey(key string) (name, email string) {
 // own email groups). Use a repository .mailmap to unify a person's several
 // emails. The displayed name is the spelling with the most changes, and
 // Identities lists the tokens (emails, or names for email-less identities) that
-// reproduce this group as a user filter.
-func mergeAuthorAliases(editions map[string][2]int) []Contributor {
+// reproduce this group as a user filter. Editions are [additions, deletions,
+// commits, co-authored commits] per identity; offsets are the commits per UTC
+// offset of each identity, summed per group as well.
+func mergeAuthorAliases(editions map[string][4]int, offsets map[string]map[string]int) []Contributor {
 	type group struct {
 		additions, deletions int
+		commits, coAuthored  int
 		emails               map[string]bool // distinct emails
 		nameTotals           map[string]int  // name spelling -> total changes
+		offsets              map[string]int  // UTC offset -> commits
 	}
 	groups := map[string]*group{}
 
@@ -42,17 +46,19 @@ func mergeAuthorAliases(editions map[string][2]int) []Contributor {
 		name = strings.TrimSpace(name)
 		email = strings.TrimSpace(email)
 
-		groupKey := "email:" + strings.ToLower(email)
-		if email == "" {
-			groupKey = "name:" + strings.ToLower(name)
-		}
+		groupKey := aliasGroupKey(name, email)
 		g := groups[groupKey]
 		if g == nil {
-			g = &group{emails: map[string]bool{}, nameTotals: map[string]int{}}
+			g = &group{emails: map[string]bool{}, nameTotals: map[string]int{}, offsets: map[string]int{}}
 			groups[groupKey] = g
 		}
 		g.additions += e[0]
 		g.deletions += e[1]
+		g.commits += e[2]
+		g.coAuthored += e[3]
+		for offset, n := range offsets[key] {
+			g.offsets[offset] += n
+		}
 		if email != "" {
 			g.emails[email] = true
 		}
@@ -62,7 +68,7 @@ func mergeAuthorAliases(editions map[string][2]int) []Contributor {
 	}
 
 	contributors := make([]Contributor, 0, len(groups))
-	for _, g := range groups {
+	for groupKey, g := range groups {
 		identities := make([]string, 0, len(g.emails))
 		for em := range g.emails {
 			identities = append(identities, em)
@@ -78,12 +84,19 @@ func mergeAuthorAliases(editions map[string][2]int) []Contributor {
 			Additions:  g.additions,
 			Deletions:  g.deletions,
 			Total:      g.additions + g.deletions,
+			Commits:    g.commits,
+			CoAuthored: g.coAuthored,
 			Identities: identities,
+			UTCOffsets: offsetCounts(g.offsets),
+			key:        groupKey,
 		})
 	}
 
 	sort.Slice(contributors, func(i, j int) bool {
-		return contributors[i].Total > contributors[j].Total
+		if contributors[i].Total != contributors[j].Total {
+			return contributors[i].Total > contributors[j].Total
+		}
+		return contributors[i].Author < contributors[j].Author
 	})
 	return contributors
 }
@@ -117,3 +130,14 @@ func nicerName(a, b string) bool {
 	}
 	return a < b
 }
+
+// aliasGroupKey is the key identities are grouped by into one contributor: the
+// case-insensitive email, or the name when there is no email.
+func aliasGroupKey(name, email string) string {
+	name = strings.TrimSpace(name)
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "name:" + strings.ToLower(name)
+	}
+	return "email:" + strings.ToLower(email)
+}
@@ -35,10 +35,10 @@ func TestNicerName(t *testing.T) {
 }
 
 func TestMergeAuthorAliasesSameEmailHigherVolumeWins(t *testing.T) {
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("romain.guisset", "r@e"): {10, 0},
 		edKey("Romain Guisset", "r@e"): {5, 0},
-	})
+	}, nil)
 	if len(got) != 1 {
 		t.Fatalf("want 1 contributor, got %d: %+v", len(got), got)
 	}
@@ -51,20 +51,20 @@ func TestMergeAuthorAliasesSameEmailHigherVolumeWins(t *testing.T) {
 }
 
 func TestMergeAuthorAliasesTiePrefersProperName(t *testing.T) {
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("romain.guisset", "r@e"): {5, 0},
 		edKey("Romain Guisset", "r@e"): {5, 0},
-	})
+	}, nil)
 	if len(got) != 1 || got[0].Author != "Romain Guisset" {
 		t.Errorf("want single contributor 'Romain Guisset', got %+v", got)
 	}
 }
 
 func TestMergeAuthorAliasesSameNameTwoEmailsStaySeparate(t *testing.T) {
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("Alice", "a@x"): {1, 0},
 		edKey("Alice", "a@y"): {1, 0},
-	})
+	}, nil)
 	if len(got) != 2 {
 		t.Fatalf("want 2 contributors (one per email), got %d: %+v", len(got), got)
 	}
@@ -72,12 +72,12 @@ func TestMergeAuthorAliasesSameNameTwoEmailsStaySeparate(t *testing.T) {
 
 func TestMergeAuthorAliasesNoBridgeThroughBot(t *testing.T) {
 	// A bot authoring under two humans' emails must not bridge them.
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("Alice", "a@x"): {1, 0},
 		edKey("bot", "a@x"):   {1, 0},
 		edKey("Bob", "b@y"):   {1, 0},
 		edKey("bot", "b@y"):   {1, 0},
-	})
+	}, nil)
 	if len(got) != 2 {
 		t.Fatalf("want 2 contributors (one per email, no bridge), got %d: %+v", len(got), got)
 	}
@@ -91,9 +91,9 @@ func TestMergeAuthorAliasesNoBridgeThroughBot(t *testing.T) {
 }
 
 func TestMergeAuthorAliasesEmailless(t *testing.T) {
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("Solo", ""): {3, 0},
-	})
+	}, nil)
 	if len(got) != 1 || got[0].Author != "Solo" || got[0].Total != 3 {
 		t.Fatalf("want Solo/3, got %+v", got)
 	}
@@ -103,11 +103,11 @@ func TestMergeAuthorAliasesEmailless(t *testing.T) {
 }
 
 func TestMergeAuthorAliasesSortedByTotal(t *testing.T) {
-	got := mergeAuthorAliases(map[string][2]int{
+	got := mergeAuthorAliases(map[string][4]int{
 		edKey("Small", "s@e"): {1, 0},
 		edKey("Big", "b@e"):   {10, 0},
 		edKey("Mid", "m@e"):   {5, 0},
-	})
+	}, nil)
 	if len(got) != 3 {
 		t.Fatalf("want 3, got %d", len(got))
 	}
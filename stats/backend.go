@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Backends reading the repository histories.
+const (
+	BackendGoGit = "go-git" // pure Go, computes diffs lazily (the default)
+	BackendGit   = "git"    // the git command line, one git log --numstat per scan
+)
+
+// historyBackend reads commits out of a repository.
+type historyBackend interface {
+	// commits returns the commits reachable from tips that h does not hold
+	// yet. Every commit reachable from h.heads is known to be held by h.
+	commits(tips []string, h *repoHistory) ([]*commitFact, error)
+	// diff computes the per-file changes of a commit.
+	diff(fact *commitFact) ([]fileChange, error)
+	// blame counts the lines of a file at the head commit per author.
+	blame(head, path string) (map[identity]int, error)
+}
+
+// ParseBackend validates a backend name; an empty name selects the default.
+func ParseBackend(name string) (string, error) {
+	switch name {
+	case "":
+		return BackendGoGit, nil
+	case BackendGoGit, BackendGit:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown backend %q, use %s or %s", name, BackendGoGit, BackendGit)
+	}
+}
+
+// newBackend returns the backend named kind for the repository at path. The
+// git backend falls back to go-git when no git binary, or one older than
+// minGitVersion, is installed.
+func newBackend(kind, path string, repo *git.Repository) historyBackend {
+	goGit := &goGitBackend{repo: repo}
+	if kind == BackendGit {
+		if bin := gitBinary(); bin != "" {
+			return &gitBackend{bin: bin, path: path, fallback: goGit}
+		}
+	}
+	return goGit
+}
+
+// goGitBackend reads the history with go-git, one commit object at a time.
+type goGitBackend struct {
+	repo *git.Repository
+}
+
+func (b *goGitBackend) commits(tips []string, h *repoHistory) ([]*commitFact, error) {
+	seen := map[string]bool{}
+	var fresh []*commitFact
+	stack := append([]string{}, tips...)
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[hash] || h.commits[hash] != nil {
+			continue
+		}
+		seen[hash] = true
+		c, err := b.repo.CommitObject(plumbing.NewHash(hash))
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			// Shallow clone boundary: the history stops here.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		fact := newCommitFact(c)
+		fresh = append(fresh, fact)
+		stack = append(stack, fact.Parents...)
+	}
+	return fresh, nil
+}
+
+func (b *goGitBackend) diff(fact *commitFact) ([]fileChange, error) {
+	c, err := b.repo.CommitObject(plumbing.NewHash(fact.Hash))
+	if err != nil {
+		return nil, err
+	}
+	stats, err := c.Stats()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]fileChange, 0, len(stats))
+	for _, stat := range stats {
+		files = append(files, fileChange{Name: stat.Name, Additions: stat.Addition, Deletions: stat.Deletion})
+	}
+	return files, nil
+}
+
+// newCommitFact records the header of a commit; its files are left to diff.
+func newCommitFact(c *object.Commit) *commitFact {
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+	return &commitFact{
+		Hash:           c.Hash.String(),
+		Parents:        parents,
+		AuthorName:     c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		AuthorWhen:     c.Author.When,
+		CommitterName:  c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommitterWhen:  c.Committer.When,
+		Subject:        commitSubject(c.Message),
+		Type:           commitType(c.Message),
+		CoAuthors:      coAuthors(c.Message),
+	}
+}
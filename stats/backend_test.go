@@ -0,0 +1,190 @@
+package stats
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestParseBackend(t *testing.T) {
+	for name, want := range map[string]string{"": BackendGoGit, "go-git": BackendGoGit, "git": BackendGit} {
+		got, err := ParseBackend(name)
+		if err != nil || got != want {
+			t.Errorf("ParseBackend(%q) = (%q, %v), want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseBackend("svn"); err == nil {
+		t.Error("an unknown backend should be an error")
+	}
+}
+
+func TestParseGitLog(t *testing.T) {
+	out := "\x1eabc\x1fp1 p2\x1fAlice\x1fa@e\x1f2026-03-02T10:00:00+02:00\x1fCi\x1fci@e\x1f2026-03-03T09:00:00Z" +
+		"\x1fBob <b@e>\x1dCarol <c@e>\x1ffeat(x): add\n" +
+		"\n" +
+		"3\t1\tmain.go\n" +
+		"-\t-\tlogo.png\n" +
+		"1\t0\t\"sp\\303\\251cial.txt\"\n" +
+		"\x1edef\x1f\x1fBob\x1fb@e\x1f2026-03-01T10:00:00Z\x1fBob\x1fb@e\x1f2026-03-01T10:00:00Z\x1f\x1fwip\n"
+	facts, err := parseGitLog(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 2 {
+		t.Fatalf("want 2 commits, got %d", len(facts))
+	}
+	a := facts[0]
+	if a.Hash != "abc" || !reflect.DeepEqual(a.Parents, []string{"p1", "p2"}) || a.AuthorEmail != "a@e" || a.CommitterEmail != "ci@e" || a.Type != "feat" || a.Subject != "feat(x): add" {
+		t.Errorf("first commit = %+v", a)
+	}
+	if want := []identity{{Name: "Bob", Email: "b@e"}, {Name: "Carol", Email: "c@e"}}; !reflect.DeepEqual(a.CoAuthors, want) {
+		t.Errorf("co-authors = %+v, want %+v", a.CoAuthors, want)
+	}
+	if _, offset := a.AuthorWhen.Zone(); offset != 2*3600 {
+		t.Errorf("author date offset = %d, want the recorded +02:00", offset)
+	}
+	wantFiles := []fileChange{{"main.go", 3, 1}, {"spécial.txt", 1, 0}}
+	if !reflect.DeepEqual(a.Files, wantFiles) || !a.Diffed {
+		t.Errorf("files = %+v, want %+v", a.Files, wantFiles)
+	}
+	if b := facts[1]; len(b.Parents) != 0 || b.Type != "other" || len(b.Files) != 0 || len(b.CoAuthors) != 0 {
+		t.Errorf("second commit = %+v", b)
+	}
+}
+
+func TestBackendsAgree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.FixedZone("", 3600))
+	commitFile(t, path, "a.go", "a\nb\nc\n", "feat: a", "alice", when)
+	commitFile(t, path, "a.go", "a\nc\nd\n", "fix: a", "bob", when.Add(time.Hour))
+	commitFile(t, path, "docs/readme.md", "hello\n", "docs: readme\n\nCo-authored-by: Bob <bob@example.com>", "alice", when.Add(2*time.Hour))
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(kind string) map[string]*commitFact {
+		backend := newBackend(kind, path, repo)
+		h := newRepoHistory()
+		facts, err := h.update(headOf(t, repo), nil, backend)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		byHash := map[string]*commitFact{}
+		for _, f := range facts {
+			f.Files = h.diff(backend, f)
+			sort.Slice(f.Files, func(i, j int) bool { return f.Files[i].Name < f.Files[j].Name })
+			byHash[f.Hash] = f
+		}
+		return byHash
+	}
+	goGit, cli := read(BackendGoGit), read(BackendGit)
+	if len(goGit) != 3 || len(cli) != 3 {
+		t.Fatalf("want 3 commits from each backend, got %d and %d", len(goGit), len(cli))
+	}
+	for hash, g := range goGit {
+		c := cli[hash]
+		if c == nil {
+			t.Fatalf("git backend misses commit %s", hash)
+		}
+		if g.AuthorName != c.AuthorName || g.AuthorEmail != c.AuthorEmail || g.Type != c.Type || g.Subject != c.Subject ||
+			g.CommitterName != c.CommitterName || !g.CommitterWhen.Equal(c.CommitterWhen) ||
+			!g.AuthorWhen.Equal(c.AuthorWhen) || !reflect.DeepEqual(g.Parents, c.Parents) ||
+			!reflect.DeepEqual(g.Files, c.Files) || !reflect.DeepEqual(g.CoAuthors, c.CoAuthors) {
+			t.Errorf("backends disagree on %s:\ngo-git %+v\ngit    %+v", hash, g, c)
+		}
+	}
+}
+
+func TestGitBackendIsIncremental(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := newBackend(BackendGit, path, repo)
+	h := newRepoHistory()
+	if _, err := h.update(headOf(t, repo), nil, backend); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, path, "b.go", "b\n", "fix: b", "bob", when)
+	fresh, err := backend.commits([]string{headHash(t, repo)}, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fresh) != 1 || fresh[0].Type != "fix" {
+		t.Errorf("want only the new commit, got %+v", fresh)
+	}
+}
+
+func TestParseGitVersion(t *testing.T) {
+	for out, want := range map[string][2]int{
+		"git version 2.39.5\n":                   {2, 39},
+		"git version 2.37.1 (Apple Git-137.1)\n": {2, 37},
+		"git version 2.45.2.windows.1\n":         {2, 45},
+	} {
+		if got, ok := parseGitVersion(out); !ok || got != want {
+			t.Errorf("parseGitVersion(%q) = %v, %t, want %v", out, got, ok, want)
+		}
+	}
+	if _, ok := parseGitVersion("hub version 2.14"); ok {
+		t.Error("an unexpected output should not parse")
+	}
+}
+
+func TestGitBackendFallsBack(t *testing.T) {
+	bin, err := exec.LookPath("false")
+	if err != nil {
+		t.Skip("false is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A git whose log always fails: the history is read with go-git.
+	backend := &gitBackend{bin: bin, path: path, fallback: &goGitBackend{repo: repo}}
+	h := newRepoHistory()
+	facts, err := h.update(headOf(t, repo), nil, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 1 || len(h.diff(backend, facts[0])) != 1 {
+		t.Errorf("want the commit and its file from the fallback, got %+v", facts)
+	}
+}
+
+func headHash(t *testing.T, repo *git.Repository) string {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return head.Hash().String()
+}
+
+// headOf returns the HEAD tip of a repository, as resolveHeads does by default.
+func headOf(t *testing.T, repo *git.Repository) map[string]string {
+	return map[string]string{"HEAD": headHash(t, repo)}
+}
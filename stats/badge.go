@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"time"
+)
+
+// Badge metrics.
+const (
+	BadgeCommits      = "commits"      // commits in the window
+	BadgeContributors = "contributors" // contributors in the window
+	BadgeStreak       = "streak"       // current streak of days with commits
+	BadgeTopLanguage  = "top-language" // language with the most lines changed
+	BadgeLastCommit   = "last-commit"  // age of the last commit
+)
+
+// BadgeMetrics lists the badge metrics.
+var BadgeMetrics = []string{BadgeCommits, BadgeContributors, BadgeStreak, BadgeTopLanguage, BadgeLastCommit}
+
+// Badge colors, like shields.io.
+const (
+	badgeGreen     = "#4c1"
+	badgeYellow    = "#dfb317"
+	badgeOrange    = "#fe7d37"
+	badgeRed       = "#e05d44"
+	badgeBlue      = "#007ec6"
+	badgeLightGrey = "#9f9f9f"
+)
+
+// ParseBadge validates a badge metric.
+func ParseBadge(value string) (string, error) {
+	return parseChoice("badge", value, BadgeMetrics)
+}
+
+// WriteBadge renders a metric of the aggregated statistics as a flat
+// shields-style SVG badge.
+func WriteBadge(w io.Writer, agg AggregatedStats, metric string, now time.Time) error {
+	label, value, color, err := badgeValue(agg, metric, now)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, badgeSVG(label, value, color))
+	return err
+}
+
+// badgeValue is the label, value and color of a badge.
+func badgeValue(agg AggregatedStats, metric string, now time.Time) (string, string, string, error) {
+	switch metric {
+	case BadgeCommits:
+		return "commits", fmt.Sprint(agg.TotalCommits), activeColor(agg.TotalCommits), nil
+	case BadgeContributors:
+		return "contributors", fmt.Sprint(len(agg.Contributors)), activeColor(len(agg.Contributors)), nil
+	case BadgeStreak:
+		streak := currentStreak(agg.Calendar, now)
+		return "streak", plural(streak, "day"), activeColor(streak), nil
+	case BadgeTopLanguage:
+		if len(agg.Languages) == 0 || agg.Languages[0].Total == 0 {
+			return "top language", "none", badgeLightGrey, nil
+		}
+		return "top language", agg.Languages[0].Name, badgeBlue, nil
+	case BadgeLastCommit:
+		last := lastCommit(agg)
+		if last.IsZero() {
+			return "last commit", "never", badgeLightGrey, nil
+		}
+		return "last commit", commitAge(last, now), ageColor(now.Sub(last)), nil
+	}
+	_, err := ParseBadge(metric)
+	return "", "", "", err
+}
+
+// currentStreak counts the days with commits back from today, like the web
+// UI: the calendar may extend past today to the end of the week.
+func currentStreak(calendar []DayCount, now time.Time) int {
+	today := now.Format(dateLayout)
+	end := len(calendar) - 1
+	for end >= 0 && calendar[end].Date > today {
+		end--
+	}
+	streak := 0
+	for i := end; i >= 0 && calendar[i].Count > 0; i-- {
+		streak++
+	}
+	return streak
+}
+
+// lastCommit is the latest commit of the contributors, active or not.
+func lastCommit(agg AggregatedStats) time.Time {
+	var last time.Time
+	for _, c := range agg.Contributors {
+		if c.LastCommit.After(last) {
+			last = c.LastCommit
+		}
+	}
+	for _, c := range agg.InactiveContributors {
+		if c.LastCommit.After(last) {
+			last = c.LastCommit
+		}
+	}
+	return last
+}
+
+// commitAge reads like "today", "3 days ago" or "2 months ago".
+func commitAge(when, now time.Time) string {
+	days := int(now.Sub(when).Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days < 60:
+		return plural(days, "day") + " ago"
+	case days < 730:
+		return plural(days/30, "month") + " ago"
+	default:
+		return plural(days/365, "year") + " ago"
+	}
+}
+
+func activeColor(n int) string {
+	if n > 0 {
+		return badgeGreen
+	}
+	return badgeLightGrey
+}
+
+func ageColor(age time.Duration) string {
+	switch days := age.Hours() / 24; {
+	case days < 7:
+		return badgeGreen
+	case days < 30:
+		return badgeYellow
+	case days < 90:
+		return badgeOrange
+	default:
+		return badgeRed
+	}
+}
+
+// badgeSVG draws a flat badge: the label on grey, the value on color. Text
+// widths are estimated, at about 7 pixels per character.
+func badgeSVG(label, value, color string) string {
+	lw, vw := 10+7*len([]rune(label)), 10+7*len([]rune(value))
+	label, value = html.EscapeString(label), html.EscapeString(value)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="%[2]d" height="20" fill="#555"/>
+<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+<rect width="%[1]d" height="20" fill="url(#s)"/>
+</g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text>
+<text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text>
+<text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, lw+vw, lw, vw, label, value, color, lw/2, lw+vw/2)
+}
@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBadgeValue(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	agg := AggregatedStats{
+		TotalCommits:         12,
+		Contributors:         []Contributor{{Author: "alice", LastCommit: now.AddDate(0, 0, -2)}},
+		InactiveContributors: []ContributorActivity{{Author: "bob", LastCommit: now.AddDate(0, -5, 0)}},
+		Languages:            []Language{{Name: "Go", Total: 30}},
+		Calendar: []DayCount{
+			{Date: "2026-03-02", Count: 0}, {Date: "2026-03-03", Count: 1}, {Date: "2026-03-04", Count: 2},
+			{Date: "2026-03-05", Count: 1}, {Date: "2026-03-06", Count: 0}, {Date: "2026-03-07", Count: 0},
+		},
+	}
+	for _, tc := range []struct {
+		metric, value, color string
+	}{
+		{BadgeCommits, "12", badgeGreen},
+		{BadgeContributors, "1", badgeGreen},
+		{BadgeStreak, "3 days", badgeGreen},
+		{BadgeTopLanguage, "Go", badgeBlue},
+		{BadgeLastCommit, "2 days ago", badgeGreen},
+	} {
+		_, value, color, err := badgeValue(agg, tc.metric, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != tc.value || color != tc.color {
+			t.Errorf("%s badge %q %s, want %q %s", tc.metric, value, color, tc.value, tc.color)
+		}
+	}
+
+	_, value, color, _ := badgeValue(AggregatedStats{InactiveContributors: agg.InactiveContributors}, BadgeLastCommit, now)
+	if value != "5 months ago" || color != badgeRed {
+		t.Errorf("last commit badge %q %s, want 5 months ago in red", value, color)
+	}
+	if _, _, _, err := badgeValue(agg, "stars", now); err == nil {
+		t.Error("an unknown badge should be rejected")
+	}
+}
+
+func TestWriteBadge(t *testing.T) {
+	var buf bytes.Buffer
+	agg := AggregatedStats{Languages: []Language{{Name: "C & <C++>", Total: 1}}}
+	if err := WriteBadge(&buf, agg, BadgeTopLanguage, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "C &amp; &lt;C++&gt;") {
+		t.Errorf("the value should be escaped:\n%s", buf.String())
+	}
+	d := xml.NewDecoder(&buf)
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SurvivingCount is how many lines of the HEAD tree are attributed to a
+// contributor or a language by git blame.
+type SurvivingCount struct {
+	Name  string `json:"name"`
+	Lines int    `json:"lines"`
+}
+
+// SurvivingLines is who wrote the code that exists today: the lines of the
+// HEAD tree of every repository, attributed by git blame.
+type SurvivingLines struct {
+	Total        int              `json:"total"`
+	Contributors []SurvivingCount `json:"contributors"` // the most lines first
+	Languages    []SurvivingCount `json:"languages"`    // the most lines first
+}
+
+// blameKey identifies a blamed file: a path and its content. The lines of a
+// file that did not change keep their authors.
+func blameKey(path string, blob plumbing.Hash) string {
+	return path + "\x00" + blob.String()
+}
+
+// countSurvivingLines blames every file of the HEAD tree that the file
+// patterns keep, and credits its lines to their mailmap-resolved authors
+// (those of the user filter only, when set) per language. Blames are kept in
+// the history, so only the files that changed since the previous pass are
+// blamed again, whatever the file patterns of the passes; the blames of the
+// files no longer in the tree are dropped.
+func countSurvivingLines(r *StatsResult, repo *git.Repository, history *repoHistory, backend historyBackend, mailmap *Mailmap, users []string, include, exclude []*regexp.Regexp) error {
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("cannot get repository HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode != filemode.Regular && f.Mode != filemode.Executable {
+			return nil
+		}
+		key := blameKey(f.Name, f.Hash)
+		present[key] = true
+		if fileIgnored(f.Name, include, exclude) {
+			return nil
+		}
+		lines, ok := history.blames[key]
+		if !ok {
+			if binary, err := f.IsBinary(); err != nil || binary {
+				return nil
+			}
+			if lines, err = backend.blame(head.Hash().String(), f.Name); err != nil {
+				// Left out, and blamed again on the next pass.
+				return nil
+			}
+		}
+		history.blames[key] = lines
+
+		lang := languageForFile(f.Name)
+		for id, n := range lines {
+			name, email := mailmap.Resolve(id.Name, id.Email)
+			if users != nil && !matchesUser(users, name, email) {
+				continue
+			}
+			authorKey := name + authorIDSep + email
+			if r.SurvivingLines[authorKey] == nil {
+				r.SurvivingLines[authorKey] = make(map[string]int)
+			}
+			r.SurvivingLines[authorKey][lang] += n
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for key := range history.blames {
+		if !present[key] {
+			delete(history.blames, key)
+		}
+	}
+	return nil
+}
+
+func (b *goGitBackend) blame(head, path string) (map[identity]int, error) {
+	commit, err := b.repo.CommitObject(plumbing.NewHash(head))
+	if err != nil {
+		return nil, err
+	}
+	result, err := git.Blame(commit, path)
+	if err != nil {
+		return nil, err
+	}
+	lines := make(map[identity]int)
+	for _, line := range result.Lines {
+		lines[identity{Name: line.AuthorName, Email: line.Author}]++
+	}
+	return lines, nil
+}
+
+func (b *gitBackend) blame(head, path string) (map[identity]int, error) {
+	cmd := exec.Command(b.bin, "-C", b.path, "blame", "--line-porcelain", head, "--", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseBlame(bytes.NewReader(out))
+}
+
+// parseBlame counts the lines per author in the output of git blame
+// --line-porcelain, where every line of the file comes after the headers of
+// the commit it is attributed to.
+func parseBlame(r io.Reader) (map[identity]int, error) {
+	lines := make(map[identity]int)
+	var author identity
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines[author]++
+		case strings.HasPrefix(line, "author "):
+			author.Name = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			author.Email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+	}
+}
+
+// buildSurvivingLines totals the surviving lines of every author per
+// contributor, their identities grouped like in the contributors ranking, and
+// per language.
+func buildSurvivingLines(lines map[string]map[string]int) *SurvivingLines {
+	s := &SurvivingLines{}
+	byAuthor := make(map[string][4]int, len(lines))
+	byLanguage := make(map[string]int)
+	for author, langs := range lines {
+		e := byAuthor[author]
+		for lang, n := range langs {
+			e[0] += n
+			byLanguage[lang] += n
+			s.Total += n
+		}
+		byAuthor[author] = e
+	}
+	for _, c := range mergeAuthorAliases(byAuthor, nil) {
+		s.Contributors = append(s.Contributors, SurvivingCount{Name: c.Author, Lines: c.Additions})
+	}
+	sortSurvivingCounts(s.Contributors)
+	s.Languages = survivingCounts(byLanguage)
+	return s
+}
+
+// survivingCounts lists counters, the largest first.
+func survivingCounts(counts map[string]int) []SurvivingCount {
+	list := make([]SurvivingCount, 0, len(counts))
+	for name, n := range counts {
+		list = append(list, SurvivingCount{Name: name, Lines: n})
+	}
+	sortSurvivingCounts(list)
+	return list
+}
+
+func sortSurvivingCounts(list []SurvivingCount) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Lines != list[j].Lines {
+			return list[i].Lines > list[j].Lines
+		}
+		return list[i].Name < list[j].Name
+	})
+}
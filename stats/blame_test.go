@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"maps"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBlame(t *testing.T) {
+	out := strings.Join([]string{
+		"1111111111111111111111111111111111111111 1 1 2",
+		"author Alice",
+		"author-mail <alice@e>",
+		"summary feat: a",
+		"\tfirst",
+		"1111111111111111111111111111111111111111 2 2",
+		"author Alice",
+		"author-mail <alice@e>",
+		"\tsecond",
+		"2222222222222222222222222222222222222222 3 3 1",
+		"author Bob",
+		"author-mail <bob@e>",
+		"\tthird",
+	}, "\n") + "\n"
+	got, err := parseBlame(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[identity]int{{Name: "Alice", Email: "alice@e"}: 2, {Name: "Bob", Email: "bob@e"}: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlame = %v, want %v", got, want)
+	}
+}
+
+func TestLaunchSurvivingLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-3 * time.Hour)
+	commitFile(t, path, "a.go", "one\ntwo\nthree\n", "feat: a", "alice", when)
+	commitFile(t, path, "a.go", "one\n2\nthree\n", "fix: a", "bob", when.Add(time.Hour))
+	commitFile(t, path, "notes.md", "n\n", "docs: notes", "bob", when.Add(2*time.Hour))
+
+	for _, backend := range []string{BackendGoGit, BackendGit} {
+		opts := LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, Backend: backend,
+			SurvivingLines: true, PatternToExclude: []string{`\.md$`}}
+		s := Aggregate(Launch(opts)).SurvivingLines
+		if s == nil {
+			t.Fatalf("%s: no surviving lines", backend)
+		}
+		want := &SurvivingLines{
+			Total:        3,
+			Contributors: []SurvivingCount{{Name: "alice", Lines: 2}, {Name: "bob", Lines: 1}},
+			Languages:    []SurvivingCount{{Name: "Go", Lines: 3}},
+		}
+		if !reflect.DeepEqual(s, want) {
+			t.Errorf("%s: surviving lines %+v, want %+v", backend, s, want)
+		}
+	}
+}
+
+func TestSurvivingLinesReuseBlames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-3 * time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+	commitFile(t, path, "b.go", "b\n", "feat: b", "alice", when)
+
+	opts := LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, SurvivingLines: true, History: NewHistoryStore()}
+	Launch(opts)
+	history := opts.History.repository(path)
+	if len(history.blames) != 2 {
+		t.Fatalf("%d files blamed, want 2", len(history.blames))
+	}
+	before := maps.Clone(history.blames)
+
+	// A pass leaving a file out keeps its blame for the next passes.
+	excluded := opts
+	excluded.PatternToExclude = []string{`^b\.go$`}
+	Launch(excluded)
+	if len(history.blames) != 2 {
+		t.Fatalf("%d files blamed after excluding b.go, want 2", len(history.blames))
+	}
+
+	commitFile(t, path, "b.go", "b\nc\n", "feat: more b", "bob", when.Add(time.Hour))
+	agg := Aggregate(Launch(opts))
+	if len(history.blames) != 2 {
+		t.Errorf("%d files blamed, want 2: the former b.go is dropped", len(history.blames))
+	}
+
+	for key, lines := range history.blames {
+		if strings.HasPrefix(key, "a.go\x00") && reflect.ValueOf(lines).Pointer() != reflect.ValueOf(before[key]).Pointer() {
+			t.Error("an unchanged file should not be blamed again")
+		}
+	}
+	if agg.SurvivingLines.Total != 3 {
+		t.Errorf("%d surviving lines, want 3", agg.SurvivingLines.Total)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,21 +16,42 @@ import (
 type Params struct {
 	Weeks    int      // 0 keeps the server default
 	Delta    string   // "" means no offset
+	Since    string   // "" means no explicit window start
+	Until    string   // "" means no explicit window end
 	User     string   // "" means no user filter
 	CountAll bool     // analyze every user (ignores User)
 	Merge    bool     // merge all folders into a single result
 	Repo     string   // "" means all repositories; otherwise a single folder
 	Include  []string // file include patterns
 	Exclude  []string // file exclude patterns
+
+	AllBranches    bool     // scan every local branch
+	RemoteBranches bool     // scan every remote-tracking branch
+	Refs           []string // explicit refs to scan instead of HEAD
+	CoAuthors      bool     // also credit Co-authored-by trailers
+	AttributeBy    string   // "" keeps the server default, else author or committer
+	Merges         string   // "" keeps the server default, else include, exclude or only
+	Timezone       string   // "" keeps the server default, else commit, local, utc or an IANA name
+	TopFiles       int      // 0 keeps the server default
+	InactiveDays   int      // 0 keeps the server default
+	SurvivingLines bool     // also blame the HEAD trees
+	Series         bool     // also build the weekly series of the top contributors
 }
 
 // cacheEntry is the persisted cache payload for a single parameter set: the
-// aggregated statistics plus the moment they were computed.
+// aggregated statistics plus the moment they were computed, and the files and
+// inactive days they were aggregated with (see viewedOpts).
 type cacheEntry struct {
-	Stats     AggregatedStats `json:"stats"`
-	UpdatedAt time.Time       `json:"updatedAt"`
+	Stats        AggregatedStats `json:"stats"`
+	UpdatedAt    time.Time       `json:"updatedAt"`
+	TopFiles     int             `json:"topFiles"`
+	InactiveDays int             `json:"inactiveDays"`
 }
 
+// maxCacheDetails is how many full aggregates (see scanOpts) the cache keeps
+// in memory, the least recently used being dropped first.
+const maxCacheDetails = 4
+
 // statsCache keeps the last computed statistics per parameter set in memory,
 // persists them to a JSON file, and refreshes them: synchronously the first
 // time a parameter set is requested, in the background afterwards. At most one
@@ -41,7 +63,17 @@ type statsCache struct {
 
 	mu         sync.RWMutex
 	entries    map[string]*cacheEntry
+	details    map[string]*cacheEntry // full aggregates of the last used keys, not persisted
+	detailKeys []string               // keys of details, the least recently used first
 	refreshing map[string]bool
+	scans      map[string]scanStats
+}
+
+// scanStats counts the scans of a parameter set since the server started.
+type scanStats struct {
+	Scans    int           // scans run
+	Failures int           // scans where a repository could not be scanned
+	Duration time.Duration // duration of the last scan
 }
 
 func newStatsCache(baseOpts LaunchOptions, ttl time.Duration, file string) *statsCache {
@@ -50,7 +82,9 @@ func newStatsCache(baseOpts LaunchOptions, ttl time.Duration, file string) *stat
 		ttl:        ttl,
 		file:       file,
 		entries:    make(map[string]*cacheEntry),
+		details:    make(map[string]*cacheEntry),
 		refreshing: make(map[string]bool),
+		scans:      make(map[string]scanStats),
 	}
 }
 
@@ -62,9 +96,34 @@ func (c *statsCache) optsFor(p Params) LaunchOptions {
 		opts.DurationInWeeks = p.Weeks
 	}
 	opts.Delta = p.Delta
+	opts.Since = p.Since
+	opts.Until = p.Until
 	opts.Merge = p.Merge
 	opts.PatternToInclude = p.Include
 	opts.PatternToExclude = p.Exclude
+	opts.AllBranches = p.AllBranches
+	opts.RemoteBranches = p.RemoteBranches
+	opts.Refs = p.Refs
+	opts.CoAuthors = p.CoAuthors
+	opts.SurvivingLines = p.SurvivingLines
+	if p.Series {
+		opts.Series = DefaultSeriesContributors
+	}
+	if p.AttributeBy != "" {
+		opts.AttributeBy = p.AttributeBy
+	}
+	if p.Merges != "" {
+		opts.Merges = p.Merges
+	}
+	if p.Timezone != "" {
+		opts.Timezone = p.Timezone
+	}
+	if p.TopFiles != 0 {
+		opts.TopFiles = p.TopFiles
+	}
+	if p.InactiveDays > 0 {
+		opts.InactiveDays = p.InactiveDays
+	}
 
 	// A specific repository restricts the scan to that single folder (its stats
 	// are then shown on their own, not grouped with the others).
@@ -83,24 +142,112 @@ func (c *statsCache) optsFor(p Params) LaunchOptions {
 	return opts
 }
 
-// cacheKey is a canonical, stable identifier for a set of launch options.
+// cacheKey is a canonical, stable identifier for the scan of a set of launch
+// options. The options that only shape the aggregation (TopFiles,
+// OwnershipDepth and InactiveDays) are left out: see scanOpts.
 func cacheKey(opts LaunchOptions) string {
 	user := "all"
 	if opts.User != nil {
 		user = *opts.User
 	}
 	return fmt.Sprintf(
-		"f=%s|w=%d|d=%s|u=%s|m=%t|inc=%s|exc=%s",
+		"f=%s|w=%d|d=%s|s=%s|t=%s|u=%s|m=%t|inc=%s|exc=%s|ab=%t|rb=%t|refs=%s|co=%t|by=%s|mg=%s|tz=%s|sl=%t|ser=%d|cd=%t",
 		strings.Join(opts.Folders, ","),
 		opts.DurationInWeeks,
 		opts.Delta,
+		opts.Since,
+		opts.Until,
 		user,
 		opts.Merge,
 		strings.Join(opts.PatternToInclude, ","),
 		strings.Join(opts.PatternToExclude, ","),
+		opts.AllBranches,
+		opts.RemoteBranches,
+		strings.Join(opts.Refs, ","),
+		opts.CoAuthors,
+		opts.AttributeBy,
+		opts.Merges,
+		opts.Timezone,
+		opts.SurvivingLines,
+		opts.Series,
+		opts.ContributorDays,
 	)
 }
 
+// viewedOpts returns the options the cached entry of a set of launch options
+// is scanned with: the files and inactive days of the server defaults, and no
+// ownership, so that the sets sharing a cacheKey share their entry. The sets
+// the entry does not cover (see covers) are aggregated from a full aggregate
+// instead (see scanOpts).
+func (c *statsCache) viewedOpts(opts LaunchOptions) LaunchOptions {
+	opts.TopFiles, opts.InactiveDays = resolvedView(c.baseOpts)
+	opts.OwnershipDepth = 0
+	return opts
+}
+
+// scanOpts returns the options of the full aggregate of a set of launch
+// options: every file, directory level, inactive contributor and commit is
+// kept. viewOf then applies the set's own TopFiles, OwnershipDepth and
+// InactiveDays to it.
+func scanOpts(opts LaunchOptions) LaunchOptions {
+	opts.TopFiles = -1
+	opts.OwnershipDepth = -1
+	opts.InactiveDays = -1
+	opts.Records = true
+	return opts
+}
+
+// resolvedView returns the TopFiles and InactiveDays of a set of launch
+// options, their defaults applied.
+func resolvedView(opts LaunchOptions) (top, inactiveDays int) {
+	top, inactiveDays = opts.TopFiles, opts.InactiveDays
+	if top == 0 {
+		top = DefaultTopFiles
+	}
+	if inactiveDays == 0 {
+		inactiveDays = DefaultInactiveDays
+	}
+	return top, inactiveDays
+}
+
+// covers reports whether the statistics of an entry hold every file, inactive
+// contributor and directory the options ask for.
+func (e *cacheEntry) covers(opts LaunchOptions) bool {
+	top, inactiveDays := resolvedView(opts)
+	return opts.OwnershipDepth == 0 && inactiveDays == e.InactiveDays &&
+		(e.TopFiles < 0 || (top >= 0 && top <= e.TopFiles))
+}
+
+// viewOf returns the statistics of a scan covering opts (see scanOpts and
+// covers) as aggregated with the TopFiles, OwnershipDepth and InactiveDays of
+// opts.
+func viewOf(agg AggregatedStats, opts LaunchOptions) AggregatedStats {
+	top, inactiveDays := resolvedView(opts)
+	agg.Files = topFiles(agg.Files, top)
+
+	cutoff := agg.EndOfScan.AddDate(0, 0, -inactiveDays)
+	var inactive []ContributorActivity
+	for _, a := range agg.InactiveContributors {
+		if inactiveDays < 0 || a.LastCommit.Before(cutoff) {
+			inactive = append(inactive, a)
+		}
+	}
+	agg.InactiveContributors = inactive
+
+	var ownership []DirectoryOwnership
+	for _, d := range agg.Ownership {
+		if opts.OwnershipDepth == 0 || (opts.OwnershipDepth > 0 && ownershipLevel(d.Path) > opts.OwnershipDepth) {
+			continue
+		}
+		d.TopOwnerInactive = d.Owners[0].LastCommit.Before(cutoff)
+		ownership = append(ownership, d)
+	}
+	agg.Ownership = ownership
+	// The commits are only listed by /api/commits, see commitsFor.
+	agg.Commits = nil
+	return agg
+}
+
 // containsFolder reports whether folder is one of the folders.
 func containsFolder(folders []string, folder string) bool {
 	for _, f := range folders {
@@ -161,15 +308,25 @@ func (c *statsCache) persist() {
 }
 
 // scan runs a full analysis for the given options and stores the result under
-// its key.
+// its key. The full aggregate of the key, if any, is dropped: it is rebuilt
+// from the fresh history on next use.
 func (c *statsCache) scan(key string, opts LaunchOptions) {
 	start := time.Now()
 	log.Printf("Analyzing commits (%s)", describeOpts(opts))
 
-	stats := Aggregate(Launch(opts))
-	entry := &cacheEntry{Stats: stats, UpdatedAt: time.Now()}
+	scanned := c.viewedOpts(opts)
+	stats := Aggregate(Launch(scanned))
+	entry := &cacheEntry{Stats: stats, UpdatedAt: time.Now(), TopFiles: scanned.TopFiles, InactiveDays: scanned.InactiveDays}
 	c.mu.Lock()
 	c.entries[key] = entry
+	c.dropDetail(key)
+	s := c.scans[key]
+	s.Scans++
+	if stats.Errors > 0 {
+		s.Failures++
+	}
+	s.Duration = time.Since(start)
+	c.scans[key] = s
 	c.mu.Unlock()
 	c.persist()
 
@@ -188,9 +345,45 @@ func describeOpts(o LaunchOptions) string {
 	if o.Delta != "" {
 		desc += ", delta=" + o.Delta
 	}
+	if o.Since != "" {
+		desc += ", since=" + o.Since
+	}
+	if o.Until != "" {
+		desc += ", until=" + o.Until
+	}
 	if o.Merge {
 		desc += ", merged"
 	}
+	if o.AllBranches {
+		desc += ", all branches"
+	}
+	if o.RemoteBranches {
+		desc += ", remote branches"
+	}
+	if len(o.Refs) > 0 {
+		desc += ", refs=" + strings.Join(o.Refs, ",")
+	}
+	if o.CoAuthors {
+		desc += ", co-authors"
+	}
+	if o.AttributeBy == AttributeCommitter {
+		desc += ", by committer"
+	}
+	if o.Merges != "" && o.Merges != MergesInclude {
+		desc += ", merges=" + o.Merges
+	}
+	if o.Timezone != "" && o.Timezone != TimezoneCommit {
+		desc += ", timezone=" + o.Timezone
+	}
+	if o.SurvivingLines {
+		desc += ", surviving lines"
+	}
+	if o.Series > 0 {
+		desc += ", series"
+	}
+	if o.ContributorDays {
+		desc += ", contributor days"
+	}
 	return desc
 }
 
@@ -216,6 +409,77 @@ func (c *statsCache) refreshInBackground(key string, opts LaunchOptions) bool {
 	return true
 }
 
+// entryFor returns the cached entry for a set of options, scanning it
+// synchronously on first use and refreshing it in the background once stale
+// (stale-while-revalidate), with its statistics aggregated for the options
+// (see viewOf): from the full aggregate of the key when the entry does not
+// cover them. The entry is nil when the scan failed.
+func (c *statsCache) entryFor(opts LaunchOptions) (entry *cacheEntry, stale, refreshing bool) {
+	key := cacheKey(opts)
+	entry, stale, refreshing = c.state(key)
+	switch {
+	case entry == nil:
+		c.scan(key, opts)
+		entry, stale, refreshing = c.state(key)
+	case stale:
+		refreshing = c.refreshInBackground(key, opts) || refreshing
+	}
+	if entry == nil {
+		return nil, stale, refreshing
+	}
+	if !entry.covers(opts) {
+		entry = c.detail(key, opts)
+	}
+	return &cacheEntry{Stats: viewOf(entry.Stats, opts), UpdatedAt: entry.UpdatedAt}, stale, refreshing
+}
+
+// detail returns the full aggregate of a key (see scanOpts), building it on
+// first use. It is built from the history store the scans keep, so only the
+// commits new since the last scan are read.
+func (c *statsCache) detail(key string, opts LaunchOptions) *cacheEntry {
+	c.mu.Lock()
+	if d := c.details[key]; d != nil {
+		c.useDetail(key)
+		c.mu.Unlock()
+		return d
+	}
+	c.mu.Unlock()
+
+	d := &cacheEntry{Stats: Aggregate(Launch(scanOpts(opts))), UpdatedAt: time.Now(), TopFiles: -1, InactiveDays: -1}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[key] = d
+	c.useDetail(key)
+	for len(c.detailKeys) > maxCacheDetails {
+		delete(c.details, c.detailKeys[0])
+		c.detailKeys = c.detailKeys[1:]
+	}
+	return d
+}
+
+// useDetail marks the full aggregate of a key as the most recently used.
+// Callers hold mu.
+func (c *statsCache) useDetail(key string) {
+	c.detailKeys = slices.DeleteFunc(c.detailKeys, func(k string) bool { return k == key })
+	c.detailKeys = append(c.detailKeys, key)
+}
+
+// dropDetail forgets the full aggregate of a key. Callers hold mu.
+func (c *statsCache) dropDetail(key string) {
+	delete(c.details, key)
+	c.detailKeys = slices.DeleteFunc(c.detailKeys, func(k string) bool { return k == key })
+}
+
+// commitsFor returns the commits of a set of options, like entryFor, from the
+// full aggregate of its key: they are neither in the entries nor persisted.
+// ok is false when the scan failed.
+func (c *statsCache) commitsFor(opts LaunchOptions) (commits []CommitRecord, ok bool) {
+	if entry, _, _ := c.entryFor(opts); entry == nil {
+		return nil, false
+	}
+	return c.detail(cacheKey(opts), opts).Stats.Commits, true
+}
+
 // state returns the cached entry for a key along with whether it is stale
 // (older than the TTL) and whether a refresh is currently running.
 func (c *statsCache) state(key string) (entry *cacheEntry, stale, refreshing bool) {
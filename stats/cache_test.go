@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestViewOfMatchesAggregate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	now := time.Now()
+	old := &object.Signature{Name: "alice", Email: "alice@example.com", When: now.AddDate(0, 0, -120)}
+	commitAs(t, path, "billing/api/a.go", "a\nb\nc\n", "feat: billing", old, old)
+	idle := &object.Signature{Name: "carol", Email: "carol@example.com", When: now.AddDate(0, 0, -40)}
+	commitAs(t, path, "billing/b.go", "b\n", "fix: billing", idle, idle)
+	commitFile(t, path, "web/app.js", "x\n", "feat: web", "bob", now.Add(-time.Hour))
+	commitFile(t, path, "main.go", "m\n", "feat: main", "bob", now.Add(-time.Hour))
+
+	base := LaunchOptions{DurationInWeeks: 26, Folders: []string{path}, Dashboard: true}
+	scanned := Aggregate(Launch(scanOpts(base)))
+	for _, set := range [][3]int{{0, 0, 0}, {1, 1, 30}, {2, 2, 0}, {-1, 3, 100}} {
+		opts := base
+		opts.TopFiles, opts.OwnershipDepth, opts.InactiveDays = set[0], set[1], set[2]
+		want := Aggregate(Launch(opts))
+		got := viewOf(scanned, opts)
+		if !reflect.DeepEqual(got.Files, want.Files) {
+			t.Errorf("%v: files %+v, want %+v", set, got.Files, want.Files)
+		}
+		if !reflect.DeepEqual(got.Ownership, want.Ownership) {
+			t.Errorf("%v: ownership %+v, want %+v", set, got.Ownership, want.Ownership)
+		}
+		if !reflect.DeepEqual(got.InactiveContributors, want.InactiveContributors) {
+			t.Errorf("%v: inactive %+v, want %+v", set, got.InactiveContributors, want.InactiveContributors)
+		}
+	}
+}
+
+func TestCacheKeepsFullAggregatesApart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	now := time.Now()
+	commitFile(t, path, "web/app.js", "x\n", "feat: web", "bob", now.Add(-2*time.Hour))
+	commitFile(t, path, "main.go", "m\n", "feat: main", "bob", now.Add(-time.Hour))
+
+	base := LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, TopFiles: 1, History: NewHistoryStore()}
+	c := newStatsCache(base, 0, "")
+	if entry, _, _ := c.entryFor(base); entry == nil || len(entry.Stats.Files) != 1 || len(c.details) != 0 {
+		t.Fatalf("the default view should be served from the entry alone, details %v", c.details)
+	}
+
+	all := base
+	all.TopFiles = -1
+	all.OwnershipDepth = 1
+	entry, _, _ := c.entryFor(all)
+	if entry == nil || len(entry.Stats.Files) != 2 || len(entry.Stats.Ownership) != 2 || len(c.details) != 1 {
+		t.Fatalf("every file and the ownership should come from one full aggregate, got %+v", entry)
+	}
+	if stored := c.entries[cacheKey(base)]; len(stored.Stats.Files) != 1 || stored.Stats.Ownership != nil {
+		t.Errorf("the stored entry should keep the default view only, got %d files", len(stored.Stats.Files))
+	}
+	if entry.Stats.Commits != nil || c.entries[cacheKey(base)].Stats.Commits != nil {
+		t.Error("the commits should only be listed by commitsFor")
+	}
+	if commits, ok := c.commitsFor(base); !ok || len(commits) != 2 || len(c.details) != 1 {
+		t.Errorf("commitsFor = %d commits from %d full aggregates, want 2 from the same one", len(commits), len(c.details))
+	}
+
+	for weeks := 5; weeks < 5+maxCacheDetails+1; weeks++ {
+		all.DurationInWeeks = weeks
+		c.entryFor(all)
+	}
+	if len(c.details) != maxCacheDetails || len(c.detailKeys) != maxCacheDetails {
+		t.Errorf("%d full aggregates kept, want at most %d", len(c.details), maxCacheDetails)
+	}
+}
@@ -0,0 +1,42 @@
+package stats
+
+import (
+	"regexp"
+	"strings"
+)
+
+// identityValue matches a "Name <email>" trailer value.
+var identityValue = regexp.MustCompile(`^\s*(.*?)\s*<([^>]*)>\s*$`)
+
+// coAuthorKey is the trailer crediting a co-author, matched case-insensitively.
+const coAuthorKey = "co-authored-by:"
+
+// coAuthors returns the identities of the Co-authored-by trailers of a commit
+// message. Like git, trailers are only read from the last paragraph, and a
+// message made of a subject alone has none.
+func coAuthors(message string) []identity {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var ids []identity
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if len(line) < len(coAuthorKey) || !strings.EqualFold(line[:len(coAuthorKey)], coAuthorKey) {
+			continue
+		}
+		if id, ok := parseIdentity(line[len(coAuthorKey):]); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// parseIdentity parses a "Name <email>" value.
+func parseIdentity(value string) (identity, bool) {
+	m := identityValue.FindStringSubmatch(value)
+	if m == nil || (m[1] == "" && m[2] == "") {
+		return identity{}, false
+	}
+	return identity{Name: m[1], Email: m[2]}, true
+}
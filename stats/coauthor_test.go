@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoAuthors(t *testing.T) {
+	cases := []struct {
+		message string
+		want    []identity
+	}{
+		{"feat: pair\n\nbody\n\nCo-authored-by: Bob <bob@e>\nco-authored-by: Carol C <carol@e>\n",
+			[]identity{{Name: "Bob", Email: "bob@e"}, {Name: "Carol C", Email: "carol@e"}}},
+		{"fix: windows\r\n\r\nCo-Authored-By: Dan <dan@e>\r\n", []identity{{Name: "Dan", Email: "dan@e"}}},
+		// Only the last paragraph holds trailers.
+		{"feat: x\n\nCo-authored-by: Bob <bob@e>\n\nSigned-off-by: Al <al@e>", nil},
+		// A subject alone is not a trailer.
+		{"Co-authored-by: Bob <bob@e>", nil},
+		{"feat: x\n\nCo-authored-by: no email here", nil},
+	}
+	for _, c := range cases {
+		if got := coAuthors(c.message); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("coAuthors(%q) = %+v, want %+v", c.message, got, c.want)
+		}
+	}
+}
+
+func TestLaunchCreditsCoAuthors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, ".mailmap", "Bob Builder <bob@example.com> <bob@old>\n", "chore: mailmap", "alice", when)
+	commitFile(t, path, "a.go", "a\nb\n", "feat: pair\n\nCo-authored-by: bob <bob@old>", "alice", when)
+
+	launch := func(coAuthors bool, user string) AggregatedStats {
+		opts := LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, CoAuthors: coAuthors}
+		if user != "" {
+			opts.User = &user
+		}
+		return Aggregate(Launch(opts))
+	}
+
+	if agg := launch(false, "bob@example.com"); agg.TotalCommits != 0 {
+		t.Errorf("without the option, a co-author is not credited: %d commits", agg.TotalCommits)
+	}
+	agg := launch(true, "bob@example.com")
+	if agg.TotalCommits != 1 || len(agg.Contributors) != 1 {
+		t.Fatalf("want the co-authored commit only, got %d commits and %+v", agg.TotalCommits, agg.Contributors)
+	}
+	if c := agg.Contributors[0]; c.Author != "Bob Builder" || c.Commits != 1 || c.CoAuthored != 1 || c.Additions != 2 {
+		t.Errorf("co-author = %+v, want the mailmap name with 1 co-authored commit of +2", c)
+	}
+
+	all := launch(true, "")
+	byAuthor := map[string]Contributor{}
+	for _, c := range all.Contributors {
+		byAuthor[c.Author] = c
+	}
+	if a := byAuthor["alice"]; a.Commits != 2 || a.CoAuthored != 0 {
+		t.Errorf("author = %+v, want 2 authored commits", a)
+	}
+	if all.TotalCommits != 2 {
+		t.Errorf("a co-authored commit is still counted once in the calendar, got %d", all.TotalCommits)
+	}
+}
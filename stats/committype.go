@@ -43,3 +43,13 @@ func commitType(message string) string {
 	}
 	return t
 }
+
+// commitSubject is the subject of a commit message, like git log %s: its
+// first paragraph on a single line.
+func commitSubject(message string) string {
+	paragraph := strings.TrimSpace(message)
+	if i := strings.Index(paragraph, "\n\n"); i >= 0 {
+		paragraph = paragraph[:i]
+	}
+	return strings.Join(strings.Fields(paragraph), " ")
+}
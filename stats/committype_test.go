@@ -26,3 +26,16 @@ func TestCommitType(t *testing.T) {
 		}
 	}
 }
+
+func TestCommitSubject(t *testing.T) {
+	for message, want := range map[string]string{
+		"feat: add thing\n":                    "feat: add thing",
+		"fix: a long\nwrapped subject\n\nbody": "fix: a long wrapped subject", // like git log %s
+		"\n  chore: trimmed  \n":               "chore: trimmed",
+		"":                                     "",
+	} {
+		if got := commitSubject(message); got != want {
+			t.Errorf("commitSubject(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Windows a scan can be compared against.
+const (
+	CompareNone         = ""
+	ComparePrevious     = "previous" // the same number of days, just before
+	CompareYearOverYear = "yoy"      // the same dates, one year earlier
+)
+
+// ParseCompare validates a compare mode, empty meaning no comparison.
+func ParseCompare(value string) (string, error) {
+	switch value {
+	case CompareNone, ComparePrevious, CompareYearOverYear:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid compare value %q, use %s or %s", value, ComparePrevious, CompareYearOverYear)
+}
+
+// ComparedOptions returns the options scanning the window opts is compared
+// against: the window of opts is resolved, then shifted according to mode and
+// set as an explicit since/until.
+func ComparedOptions(opts LaunchOptions, mode string) (LaunchOptions, error) {
+	r := &StatsResult{}
+	populateDurationInDays(opts, r)
+	if r.Error != nil {
+		return opts, r.Error
+	}
+	begin := getBeginningOfDay(r.BeginOfScan)
+	end := getBeginningOfDay(r.EndOfScan)
+	switch mode {
+	case ComparePrevious:
+		days := r.DurationInDays
+		end = begin.AddDate(0, 0, -1)
+		begin = begin.AddDate(0, 0, -days)
+	case CompareYearOverYear:
+		begin = begin.AddDate(-1, 0, 0)
+		end = end.AddDate(-1, 0, 0)
+	default:
+		return opts, fmt.Errorf("invalid compare value %q, use %s or %s", mode, ComparePrevious, CompareYearOverYear)
+	}
+	opts.Since = begin.Format(dateLayout)
+	opts.Until = end.Format(dateLayout)
+	opts.Delta = ""
+	return opts, nil
+}
+
+// Delta is how a value changed between the compared window and the current
+// one. Percent is nil when the previous value is zero.
+type Delta struct {
+	Current  int      `json:"current"`
+	Previous int      `json:"previous"`
+	Change   int      `json:"change"`
+	Percent  *float64 `json:"percent"`
+}
+
+// NamedDelta is the Delta of a language or a commit type.
+type NamedDelta struct {
+	Name string `json:"name"`
+	Delta
+}
+
+// Comparison sums up how the statistics changed since the compared window.
+type Comparison struct {
+	Mode string `json:"mode"`
+	// BeginOfScan and EndOfScan bound the compared (previous) window.
+	BeginOfScan  time.Time    `json:"beginOfScan"`
+	EndOfScan    time.Time    `json:"endOfScan"`
+	TotalCommits Delta        `json:"totalCommits"`
+	Additions    Delta        `json:"additions"`
+	Deletions    Delta        `json:"deletions"`
+	Contributors Delta        `json:"contributors"`
+	Languages    []NamedDelta `json:"languages"`   // lines changed
+	CommitTypes  []NamedDelta `json:"commitTypes"` // commits
+}
+
+// Compare builds the Comparison of the current statistics with the ones of
+// the compared window.
+func Compare(mode string, current, previous AggregatedStats) Comparison {
+	additions, deletions := calendarLines(current)
+	prevAdditions, prevDeletions := calendarLines(previous)
+
+	currentLangs := make(map[string]int)
+	previousLangs := make(map[string]int)
+	for _, l := range current.Languages {
+		currentLangs[l.Name] = l.Total
+	}
+	for _, l := range previous.Languages {
+		previousLangs[l.Name] = l.Total
+	}
+	currentTypes := make(map[string]int)
+	previousTypes := make(map[string]int)
+	for _, t := range current.CommitTypes {
+		currentTypes[t.Type] = t.Count
+	}
+	for _, t := range previous.CommitTypes {
+		previousTypes[t.Type] = t.Count
+	}
+
+	return Comparison{
+		Mode:         mode,
+		BeginOfScan:  previous.BeginOfScan,
+		EndOfScan:    previous.EndOfScan,
+		TotalCommits: newDelta(current.TotalCommits, previous.TotalCommits),
+		Additions:    newDelta(additions, prevAdditions),
+		Deletions:    newDelta(deletions, prevDeletions),
+		Contributors: newDelta(len(current.Contributors), len(previous.Contributors)),
+		Languages:    namedDeltas(currentLangs, previousLangs),
+		CommitTypes:  namedDeltas(currentTypes, previousTypes),
+	}
+}
+
+func newDelta(current, previous int) Delta {
+	d := Delta{Current: current, Previous: previous, Change: current - previous}
+	if previous != 0 {
+		percent := float64(d.Change) / float64(previous) * 100
+		d.Percent = &percent
+	}
+	return d
+}
+
+// namedDeltas pairs up two counters, the biggest values first.
+func namedDeltas(current, previous map[string]int) []NamedDelta {
+	var deltas []NamedDelta
+	for name, n := range current {
+		deltas = append(deltas, NamedDelta{Name: name, Delta: newDelta(n, previous[name])})
+	}
+	for name, n := range previous {
+		if _, ok := current[name]; !ok {
+			deltas = append(deltas, NamedDelta{Name: name, Delta: newDelta(0, n)})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := max(deltas[i].Current, deltas[i].Previous), max(deltas[j].Current, deltas[j].Previous)
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Name < deltas[j].Name
+	})
+	return deltas
+}
+
+func calendarLines(agg AggregatedStats) (additions int, deletions int) {
+	for _, d := range agg.Calendar {
+		additions += d.Additions
+		deletions += d.Deletions
+	}
+	return additions, deletions
+}
+
+// PrintComparison writes the comparison as a table, the changes flagged with
+// up and down arrows.
+func PrintComparison(current AggregatedStats, c Comparison) {
+	Print(Header, fmt.Sprintf("%s → %s compared with %s → %s",
+		current.BeginOfScan.Format(dateLayout), current.EndOfScan.Format(dateLayout),
+		c.BeginOfScan.Format(dateLayout), c.EndOfScan.Format(dateLayout)))
+	fmt.Println()
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tCURRENT\tPREVIOUS\tCHANGE")
+	for _, row := range []NamedDelta{
+		{Name: "Commits", Delta: c.TotalCommits},
+		{Name: "Additions", Delta: c.Additions},
+		{Name: "Deletions", Delta: c.Deletions},
+		{Name: "Contributors", Delta: c.Contributors},
+	} {
+		printDeltaRow(w, row)
+	}
+	if len(c.Languages) > 0 {
+		fmt.Fprintln(w, "\t\t\t")
+		fmt.Fprintln(w, "LANGUAGES (lines)\t\t\t")
+		for _, row := range c.Languages {
+			printDeltaRow(w, row)
+		}
+	}
+	if len(c.CommitTypes) > 0 {
+		fmt.Fprintln(w, "\t\t\t")
+		fmt.Fprintln(w, "COMMIT TYPES\t\t\t")
+		for _, row := range c.CommitTypes {
+			printDeltaRow(w, row)
+		}
+	}
+	_ = w.Flush()
+}
+
+// printDeltaRow writes a table row. The colorized change is the last column,
+// so its escape codes do not skew the padding.
+func printDeltaRow(w *tabwriter.Writer, row NamedDelta) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", row.Name, row.Current, row.Previous, deltaLabel(row.Delta))
+}
+
+// deltaLabel reads like "▲ +12 (+10%)".
+func deltaLabel(d Delta) string {
+	percent := ""
+	if d.Percent != nil {
+		percent = fmt.Sprintf(" (%+.0f%%)", *d.Percent)
+	}
+	switch {
+	case d.Change > 0:
+		return colorize(Increase, fmt.Sprintf("▲ %+d%s", d.Change, percent), Console)
+	case d.Change < 0:
+		return colorize(Decrease, fmt.Sprintf("▼ %+d%s", d.Change, percent), Console)
+	}
+	return "="
+}
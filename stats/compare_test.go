@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComparedOptions(t *testing.T) {
+	for _, tc := range []struct {
+		mode, since, until   string
+		wantSince, wantUntil string
+	}{
+		{ComparePrevious, "2026-03-01", "2026-03-10", "2026-02-19", "2026-02-28"},
+		{ComparePrevious, "2026-03-10", "2026-03-10", "2026-03-09", "2026-03-09"},
+		{CompareYearOverYear, "2026-01-01", "2026-03-31", "2025-01-01", "2025-03-31"},
+	} {
+		opts, err := ComparedOptions(LaunchOptions{Since: tc.since, Until: tc.until, Delta: "1w"}, tc.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opts.Since != tc.wantSince || opts.Until != tc.wantUntil || opts.Delta != "" {
+			t.Errorf("%s %s..%s compared with %s..%s (delta %q), want %s..%s", tc.mode, tc.since, tc.until,
+				opts.Since, opts.Until, opts.Delta, tc.wantSince, tc.wantUntil)
+		}
+	}
+	if _, err := ComparedOptions(LaunchOptions{}, "later"); err == nil {
+		t.Error("an unknown compare mode should be rejected")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	current := AggregatedStats{
+		TotalCommits: 15,
+		Contributors: []Contributor{{Author: "alice"}, {Author: "bob"}},
+		Languages:    []Language{{Name: "Go", Total: 40}},
+		CommitTypes:  []CommitTypeCount{{Type: "feat", Count: 10}, {Type: "fix", Count: 5}},
+		Calendar:     []DayCount{{Additions: 30, Deletions: 2}, {Additions: 10}},
+	}
+	previous := AggregatedStats{
+		TotalCommits: 10,
+		Contributors: []Contributor{{Author: "alice"}, {Author: "bob"}},
+		Languages:    []Language{{Name: "Go", Total: 20}, {Name: "Python", Total: 50}},
+		CommitTypes:  []CommitTypeCount{{Type: "feat", Count: 10}},
+		Calendar:     []DayCount{{Additions: 20, Deletions: 4}},
+	}
+	c := Compare(ComparePrevious, current, previous)
+
+	if c.TotalCommits.Change != 5 || c.TotalCommits.Percent == nil || *c.TotalCommits.Percent != 50 {
+		t.Errorf("commits delta %+v, want +5 (+50%%)", c.TotalCommits)
+	}
+	if c.Additions.Change != 20 || c.Deletions.Change != -2 || c.Contributors.Change != 0 {
+		t.Errorf("lines and contributors deltas %+v %+v %+v", c.Additions, c.Deletions, c.Contributors)
+	}
+	var langs []string
+	for _, l := range c.Languages {
+		langs = append(langs, l.Name)
+	}
+	if !reflect.DeepEqual(langs, []string{"Python", "Go"}) || c.Languages[0].Change != -50 {
+		t.Errorf("languages %+v, want Python (gone) then Go", c.Languages)
+	}
+	fix := c.CommitTypes[1]
+	if fix.Name != "fix" || fix.Change != 5 || fix.Percent != nil {
+		t.Errorf("fix delta %+v, want +5 without percent", fix)
+	}
+}
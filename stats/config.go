@@ -12,6 +12,9 @@ type WebConfig struct {
 	Addr      *string `json:"addr,omitempty"`
 	TTL       *string `json:"ttl,omitempty"`
 	CacheFile *string `json:"cacheFile,omitempty"`
+	// MetricSets names parameter sets, as /api/stats query strings, that
+	// /metrics exposes along with the default one.
+	MetricSets map[string]string `json:"metricSets,omitempty"`
 }
 
 // Config holds the default analysis values loaded from a JSON config file.
@@ -21,9 +24,23 @@ type WebConfig struct {
 type Config struct {
 	Weeks           *int      `json:"weeks,omitempty"`
 	Delta           *string   `json:"delta,omitempty"`
+	Since           *string   `json:"since,omitempty"`
+	Until           *string   `json:"until,omitempty"`
 	User            *string   `json:"user,omitempty"`
 	CountAll        *bool     `json:"countAll,omitempty"`
 	Merge           *bool     `json:"merge,omitempty"`
+	Jobs            *int      `json:"jobs,omitempty"`
+	Backend         *string   `json:"backend,omitempty"`
+	AllBranches     *bool     `json:"allBranches,omitempty"`
+	RemoteBranches  *bool     `json:"remoteBranches,omitempty"`
+	Refs            []string  `json:"refs,omitempty"`
+	CoAuthors       *bool     `json:"coAuthors,omitempty"`
+	AttributeBy     *string   `json:"attributeBy,omitempty"`
+	Merges          *string   `json:"merges,omitempty"`
+	Timezone        *string   `json:"timezone,omitempty"`
+	TopFiles        *int      `json:"topFiles,omitempty"`
+	InactiveDays    *int      `json:"inactiveDays,omitempty"`
+	SurvivingLines  *bool     `json:"survivingLines,omitempty"`
 	Folders         []string  `json:"folders,omitempty"`
 	IncludePatterns []string  `json:"includePatterns,omitempty"`
 	ExcludePatterns []string  `json:"excludePatterns,omitempty"`
@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	ExportCSV    = "csv"    // a table, see WriteCSV
+	ExportICS    = "ics"    // the calendar, see WriteICS
+	ExportSQLite = "sqlite" // the commits, see WriteSQLite
+)
+
+// ExportFormats lists the export formats.
+var ExportFormats = []string{ExportCSV, ExportICS, ExportSQLite}
+
+// ParseExportFormat validates an export format.
+func ParseExportFormat(value string) (string, error) {
+	return parseChoice("format", value, ExportFormats)
+}
+
+// Aggregate tables that can be exported.
+const (
+	TableCalendar     = "calendar"
+	TableContributors = "contributors"
+	TableLanguages    = "languages"
+	TableCommitTypes  = "commitTypes"
+	TableRepositories = "repositories"
+	TablePunchcard    = "punchcard"
+)
+
+// ExportTables lists the exportable tables, in the order they are documented.
+var ExportTables = []string{TableCalendar, TableContributors, TableLanguages, TableCommitTypes, TableRepositories, TablePunchcard}
+
+// ParseExportTable validates the name of an exportable table.
+func ParseExportTable(value string) (string, error) {
+	return parseChoice("table", value, ExportTables)
+}
+
+// WriteCSV writes one table of the aggregated statistics as CSV, with a
+// header row. Dates are YYYY-MM-DD, weekdays Monday-first in the punchcard
+// like in the JSON.
+func WriteCSV(w io.Writer, agg AggregatedStats, table string) error {
+	var rows [][]string
+	switch table {
+	case TableCalendar:
+		rows = append(rows, []string{"date", "weekday", "commits", "additions", "deletions"})
+		for _, d := range agg.Calendar {
+			rows = append(rows, []string{d.Date, time.Weekday(d.Weekday).String(), itoa(d.Count), itoa(d.Additions), itoa(d.Deletions)})
+		}
+	case TableContributors:
+		rows = append(rows, []string{"author", "identities", "commits", "coAuthored", "additions", "deletions", "total", "firstCommit", "lastCommit"})
+		for _, c := range agg.Contributors {
+			rows = append(rows, []string{c.Author, strings.Join(c.Identities, ";"), itoa(c.Commits), itoa(c.CoAuthored),
+				itoa(c.Additions), itoa(c.Deletions), itoa(c.Total), formatDate(c.FirstCommit), formatDate(c.LastCommit)})
+		}
+	case TableLanguages:
+		rows = append(rows, []string{"language", "additions", "deletions", "total"})
+		for _, l := range agg.Languages {
+			rows = append(rows, []string{l.Name, itoa(l.Additions), itoa(l.Deletions), itoa(l.Total)})
+		}
+	case TableCommitTypes:
+		rows = append(rows, []string{"type", "commits"})
+		for _, t := range agg.CommitTypes {
+			rows = append(rows, []string{t.Type, itoa(t.Count)})
+		}
+	case TableRepositories:
+		rows = append(rows, []string{"repository", "commits", "additions", "deletions"})
+		for _, r := range agg.Repositories {
+			rows = append(rows, []string{r.Folder, itoa(r.Commits), itoa(r.Additions), itoa(r.Deletions)})
+		}
+	case TablePunchcard:
+		header := []string{"weekday"}
+		for h := 0; h < 24; h++ {
+			header = append(header, fmt.Sprintf("%02d", h))
+		}
+		rows = append(rows, header)
+		for d, hours := range agg.Punchcard {
+			row := []string{time.Weekday((d + 1) % 7).String()}
+			for _, n := range hours {
+				row = append(row, itoa(n))
+			}
+			rows = append(rows, row)
+		}
+	default:
+		_, err := ParseExportTable(table)
+		return err
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+// formatDate formats a day, empty for the zero time.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
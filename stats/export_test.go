@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	agg := AggregatedStats{
+		Calendar: []DayCount{{Date: "2026-03-02", Count: 2, Additions: 10, Deletions: 1, Weekday: 1}},
+		Contributors: []Contributor{{Author: "Doe, Jane", Identities: []string{"jane@e", "j@e"}, Commits: 2, Additions: 10, Deletions: 1, Total: 11,
+			FirstCommit: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), LastCommit: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}},
+		CommitTypes:  []CommitTypeCount{{Type: "feat", Count: 2}},
+		Repositories: []RepositoryStat{{Folder: "/src/app", Commits: 2, Additions: 10, Deletions: 1}},
+	}
+	agg.Punchcard[6][23] = 4
+
+	for _, tc := range []struct {
+		table string
+		want  string
+	}{
+		{TableCalendar, "date,weekday,commits,additions,deletions\n2026-03-02,Monday,2,10,1\n"},
+		{TableContributors, "author,identities,commits,coAuthored,additions,deletions,total,firstCommit,lastCommit\n" +
+			"\"Doe, Jane\",jane@e;j@e,2,0,10,1,11,2025-01-02,2026-03-02\n"},
+		{TableCommitTypes, "type,commits\nfeat,2\n"},
+		{TableLanguages, "language,additions,deletions,total\n"},
+		{TableRepositories, "repository,commits,additions,deletions\n/src/app,2,10,1\n"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, agg, tc.table); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tc.table, buf.String(), tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, agg, TablePunchcard); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 8 || !strings.HasPrefix(lines[1], "Monday,") || !strings.HasSuffix(lines[7], ",4") || !strings.HasPrefix(lines[7], "Sunday,") {
+		t.Errorf("punchcard:\n%s", buf.String())
+	}
+
+	if err := WriteCSV(&buf, agg, "files"); err == nil {
+		t.Error("an unknown table should be rejected")
+	}
+}
@@ -0,0 +1,231 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitLogFormat prints a commit header on a single line, starting with a record
+// separator and with its fields separated by unit separators: hash, parents,
+// author name, email and date, committer name, email and date, the
+// Co-authored-by trailer values (separated by group separators) and subject.
+const gitLogFormat = "%x1e%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI" +
+	"%x1f%(trailers:key=Co-authored-by,valueonly,unfold,separator=%x1d)%x1f%s"
+
+// minGitVersion is the oldest git the git backend runs: --diff-merges needs
+// git 2.31.
+var minGitVersion = [2]int{2, 31}
+
+// gitBinary is the git executable the git backend runs, or "" when git is not
+// installed or older than minGitVersion. It is looked up once.
+var gitBinary = sync.OnceValue(func() string {
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		return ""
+	}
+	out, err := exec.Command(bin, "version").Output()
+	if err != nil {
+		return ""
+	}
+	version, ok := parseGitVersion(string(out))
+	if !ok || version[0] < minGitVersion[0] || (version[0] == minGitVersion[0] && version[1] < minGitVersion[1]) {
+		return ""
+	}
+	return bin
+})
+
+// parseGitVersion reads the major and minor version out of the output of git
+// version, e.g. "git version 2.39.5" or "git version 2.37.1 (Apple Git-137.1)".
+func parseGitVersion(out string) ([2]int, bool) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return [2]int{}, false
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
+
+// gitBackend reads the history by running the git command line: a single
+// git log --numstat streams the headers and file changes of every new commit.
+// When git log fails, the history is read by the fallback backend instead.
+type gitBackend struct {
+	bin      string // git executable
+	path     string // repository
+	fallback historyBackend
+}
+
+// logArgs are the git log arguments shared by the history walk and the diff
+// of a single commit. Renames are not detected and merges are diffed against
+// their first parent, like go-git does.
+func (b *gitBackend) logArgs() []string {
+	return []string{
+		"-C", b.path, "-c", "core.quotePath=false", "log",
+		"--numstat", "--no-renames", "--diff-merges=first-parent",
+		"--format=" + gitLogFormat,
+	}
+}
+
+func (b *gitBackend) commits(tips []string, h *repoHistory) ([]*commitFact, error) {
+	args := append(b.logArgs(), "--ignore-missing")
+	args = append(args, tips...)
+	full := args
+	if len(h.heads) > 0 {
+		// Everything reachable from the previous heads is already known.
+		args = append(args[:len(args):len(args)], "--not")
+		for _, hash := range h.heads {
+			args = append(args, hash)
+		}
+	}
+	facts, err := b.run(args)
+	if err != nil && len(h.heads) > 0 {
+		// The previous heads may be gone after a history rewrite: read the
+		// whole history again.
+		facts, err = b.run(full)
+	}
+	if err != nil {
+		return b.fallback.commits(tips, h)
+	}
+	return facts, nil
+}
+
+func (b *gitBackend) diff(fact *commitFact) ([]fileChange, error) {
+	facts, err := b.run(append(b.logArgs(), "--no-walk", fact.Hash))
+	if err != nil {
+		return b.fallback.diff(fact)
+	}
+	if len(facts) != 1 {
+		return nil, fmt.Errorf("git log %s: commit not found", fact.Hash)
+	}
+	return facts[0].Files, nil
+}
+
+// run executes git with args and parses its streamed log output.
+func (b *gitBackend) run(args []string) ([]*commitFact, error) {
+	cmd := exec.Command(b.bin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	facts, parseErr := parseGitLog(stdout)
+	if parseErr != nil {
+		_ = cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && parseErr == nil {
+		return nil, fmt.Errorf("git log: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return facts, parseErr
+}
+
+// parseGitLog reads the output of git log --numstat --format=gitLogFormat.
+// Each commit is a header line followed by one "added<TAB>deleted<TAB>path"
+// line per changed file.
+func parseGitLog(r io.Reader) ([]*commitFact, error) {
+	var facts []*commitFact
+	var current *commitFact
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "\x1e"):
+			fact, headerErr := parseGitLogHeader(line[1:])
+			if headerErr != nil {
+				return nil, headerErr
+			}
+			current = fact
+			facts = append(facts, current)
+		case line != "" && current != nil:
+			if change, ok := parseNumstat(line); ok {
+				current.Files = append(current.Files, change)
+			}
+		}
+		if err == io.EOF {
+			return facts, nil
+		}
+	}
+}
+
+func parseGitLogHeader(line string) (*commitFact, error) {
+	fields := strings.SplitN(line, "\x1f", 10)
+	if len(fields) != 10 {
+		return nil, fmt.Errorf("unexpected git log header: %q", line)
+	}
+	authorWhen, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return nil, err
+	}
+	committerWhen, err := time.Parse(time.RFC3339, fields[7])
+	if err != nil {
+		return nil, err
+	}
+	var coAuthors []identity
+	for _, value := range strings.Split(fields[8], "\x1d") {
+		if id, ok := parseIdentity(value); ok {
+			coAuthors = append(coAuthors, id)
+		}
+	}
+	return &commitFact{
+		Hash:           fields[0],
+		Parents:        strings.Fields(fields[1]),
+		AuthorName:     fields[2],
+		AuthorEmail:    fields[3],
+		AuthorWhen:     authorWhen,
+		CommitterName:  fields[5],
+		CommitterEmail: fields[6],
+		CommitterWhen:  committerWhen,
+		Subject:        fields[9],
+		Type:           commitType(fields[9]),
+		CoAuthors:      coAuthors,
+		Diffed:         true,
+	}, nil
+}
+
+// parseNumstat parses a single numstat line.
+func parseNumstat(line string) (fileChange, bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) != 3 {
+		return fileChange{}, false
+	}
+	// Binary files report "-" counts; like go-git, they are left out.
+	additions, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fileChange{}, false
+	}
+	deletions, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fileChange{}, false
+	}
+	name := parts[2]
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+	}
+	return fileChange{Name: name, Additions: additions, Deletions: deletions}, true
+}
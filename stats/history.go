@@ -0,0 +1,272 @@
+package stats
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// fileChange is the number of lines a commit added to and removed from a file.
+type fileChange struct {
+	Name      string
+	Additions int
+	Deletions int
+}
+
+// commitFact is what a scan keeps of a single commit: enough to rebuild every
+// statistic without reading the repository again. The per-file changes are
+// the expensive part, so they are only computed (once) for the commits a scan
+// actually counts.
+type commitFact struct {
+	Hash           string
+	Parents        []string
+	AuthorName     string
+	AuthorEmail    string
+	AuthorWhen     time.Time
+	CommitterName  string
+	CommitterEmail string
+	CommitterWhen  time.Time // the scan window applies to it, like git log --since
+	Subject        string    // first paragraph of the message, on one line
+	Type           string    // Conventional Commits type
+	CoAuthors      []identity
+	Files          []fileChange
+	Diffed         bool // Files has been computed
+}
+
+// HistoryStore keeps the commit facts of every scanned repository across
+// scans, so that refreshing a repository only reads the commits that are new
+// since its last known HEAD. A nil store keeps nothing: every scan reads the
+// whole history again.
+type HistoryStore struct {
+	mu    sync.Mutex
+	repos map[string]*repoHistory
+}
+
+// NewHistoryStore returns an empty history store.
+func NewHistoryStore() *HistoryStore {
+	return &HistoryStore{repos: make(map[string]*repoHistory)}
+}
+
+// repository returns the history of the repository at path, creating it on
+// first use. Paths are compared once made absolute.
+func (s *HistoryStore) repository(path string) *repoHistory {
+	if s == nil {
+		return newRepoHistory()
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.repos[path]
+	if h == nil {
+		h = newRepoHistory()
+		s.repos[path] = h
+	}
+	return h
+}
+
+// repoHistory is the commit facts of one repository keyed by commit hash, and
+// the heads they were last read from. Every commit reachable from heads is in
+// commits, so a walk from a newer head can stop at the first known commit.
+// Callers hold mu while reading or updating it.
+type repoHistory struct {
+	mu        sync.Mutex
+	heads     map[string]string // ref name -> commit hash, the tips the commits are kept for
+	commits   map[string]*commitFact
+	reachable map[string][]*commitFact    // tip hashes -> commits reachable from them
+	blames    map[string]map[identity]int // blameKey -> lines per author, at the last HEAD blamed
+}
+
+func newRepoHistory() *repoHistory {
+	return &repoHistory{
+		heads:     make(map[string]string),
+		commits:   make(map[string]*commitFact),
+		reachable: make(map[string][]*commitFact),
+		blames:    make(map[string]map[identity]int),
+	}
+}
+
+// update brings the history up to date with the given tips (ref name -> commit
+// hash) and returns every commit reachable from them, each once even when
+// several tips reach it. refs are the tips of every ref of the repository (see
+// repoTips): the tips and the refs whose commits are known become the heads,
+// whatever scan asks, so that scans of different refs share the store. When
+// every tip is already known nothing is read; otherwise the backend only reads
+// the new commits. Whenever the heads change, the commits they do not lead to
+// anymore (after a history rewrite, or on a deleted branch) are dropped.
+func (h *repoHistory) update(tips, refs map[string]string, backend historyBackend) ([]*commitFact, error) {
+	var unknown []string
+	for _, hash := range tips {
+		if h.commits[hash] == nil {
+			unknown = append(unknown, hash)
+		}
+	}
+	if len(unknown) > 0 {
+		fresh, err := backend.commits(unknown, h)
+		if err != nil {
+			return nil, err
+		}
+		for _, fact := range fresh {
+			h.commits[fact.Hash] = fact
+		}
+	}
+	// A ref no scan asked for yet is not read: it is not a head either, as
+	// every commit a head leads to must be known.
+	heads := make(map[string]string, len(refs)+len(tips))
+	known := make(map[string]bool, len(refs))
+	for ref, hash := range refs {
+		if h.commits[hash] != nil {
+			heads[ref] = hash
+			known[hash] = true
+		}
+	}
+	for ref, hash := range tips {
+		if !known[hash] {
+			heads[ref] = hash
+		}
+	}
+	if len(unknown) > 0 || !maps.Equal(heads, h.heads) {
+		h.heads = heads
+		h.prune()
+	}
+	return h.reachableFrom(tips), nil
+}
+
+// reachableFrom lists the known commits reachable from the tips. A parent that
+// is not known is a shallow clone boundary.
+func (h *repoHistory) reachableFrom(tips map[string]string) []*commitFact {
+	hashes := make([]string, 0, len(tips))
+	for _, hash := range tips {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	key := strings.Join(hashes, ",")
+	if reachable, ok := h.reachable[key]; ok {
+		return reachable
+	}
+
+	seen := make(map[string]bool, len(h.commits))
+	var reachable []*commitFact
+	stack := hashes
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		fact := h.commits[hash]
+		if fact == nil || seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		reachable = append(reachable, fact)
+		stack = append(stack, fact.Parents...)
+	}
+	h.reachable[key] = reachable
+	return reachable
+}
+
+// prune drops the commits that none of the heads leads to anymore.
+func (h *repoHistory) prune() {
+	kept := make(map[string]*commitFact, len(h.commits))
+	for _, fact := range h.reachableFrom(h.heads) {
+		kept[fact.Hash] = fact
+	}
+	h.commits = kept
+	h.reachable = make(map[string][]*commitFact)
+}
+
+// diff returns the per-file changes of a commit, computing them on first use.
+// A commit whose changes cannot be computed yields none, and is retried on the
+// next scan.
+func (h *repoHistory) diff(backend historyBackend, fact *commitFact) []fileChange {
+	if fact.Diffed {
+		return fact.Files
+	}
+	files, err := backend.diff(fact)
+	if err != nil {
+		return nil
+	}
+	fact.Files = files
+	fact.Diffed = true
+	return files
+}
+
+// repoTips returns the commits every ref of the repository points to, keyed by
+// ref name: HEAD, the local and remote-tracking branches and the tags.
+func repoTips(repo *git.Repository) (map[string]string, error) {
+	tips := map[string]string{}
+	if head, err := repo.Head(); err == nil {
+		tips["HEAD"] = head.Hash().String()
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		hash := ref.Hash()
+		// An annotated tag points to its tag object, not to the commit.
+		if tag, err := repo.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+		tips[ref.Name().String()] = hash.String()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tips, nil
+}
+
+// resolveHeads returns the commits to scan the history from, keyed by ref
+// name: HEAD unless explicit refs are given, plus every local (and remote)
+// branch when asked. Refs that do not exist in this repository are skipped,
+// as a scan usually covers several repositories.
+func resolveHeads(repo *git.Repository, o StatsOptions) (map[string]string, error) {
+	heads := map[string]string{}
+	if len(o.Refs) == 0 {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get repository history: %w", err)
+		}
+		heads["HEAD"] = head.Hash().String()
+	}
+	for _, ref := range o.Refs {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			continue
+		}
+		heads[ref] = hash.String()
+	}
+	if o.AllBranches || o.RemoteBranches {
+		refs, err := repo.References()
+		if err != nil {
+			return nil, err
+		}
+		err = refs.ForEach(func(ref *plumbing.Reference) error {
+			name := ref.Name()
+			if ref.Type() != plumbing.HashReference {
+				return nil
+			}
+			if (o.AllBranches && name.IsBranch()) || (o.RemoteBranches && name.IsRemote()) {
+				heads[name.String()] = ref.Hash().String()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(heads) == 0 {
+		return nil, fmt.Errorf("none of the refs %s exists", strings.Join(o.Refs, ", "))
+	}
+	return heads, nil
+}
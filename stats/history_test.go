@@ -0,0 +1,255 @@
+package stats
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile writes content to name in the repository at path and commits it
+// as the given author at the given time.
+func commitFile(t *testing.T, path, name, content, message, author string, when time.Time) string {
+	t.Helper()
+	sig := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+	return commitAs(t, path, name, content, message, sig, sig)
+}
+
+// commitAs writes content to name in the repository at path and commits it
+// with distinct author and committer signatures, on top of the given parents
+// (HEAD when none).
+func commitAs(t *testing.T, path, name, content, message string, author, committer *object.Signature, parents ...plumbing.Hash) string {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(path, name)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: author, Committer: committer, Parents: parents})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestRepoHistoryUpdateIsIncremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+	commitFile(t, path, "b.go", "b\nb\n", "fix: b", "bob", when)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHistoryStore().repository(path)
+	backend := newBackend(BackendGoGit, path, repo)
+
+	first, err := h.update(headOf(t, repo), nil, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("want 2 commits, got %d", len(first))
+	}
+	known := map[string]*commitFact{}
+	for _, f := range first {
+		known[f.Hash] = f
+		if files := h.diff(backend, f); len(files) != 1 {
+			t.Errorf("commit %s: want 1 file changed, got %+v", f.Hash, files)
+		}
+	}
+
+	again, err := h.update(headOf(t, repo), nil, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &again[0] != &first[0] {
+		t.Error("an unchanged HEAD should return the stored commits as-is")
+	}
+
+	added := commitFile(t, path, "c.go", "c\n", "docs: c", "carol", when)
+	latest, err := h.update(headOf(t, repo), nil, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest) != 3 {
+		t.Fatalf("want 3 commits, got %d", len(latest))
+	}
+	for _, f := range latest {
+		if f.Hash == added {
+			if f.Diffed || f.Type != "docs" || f.AuthorName != "carol" {
+				t.Errorf("new commit fact = %+v", f)
+			}
+			continue
+		}
+		if known[f.Hash] != f || !f.Diffed {
+			t.Errorf("commit %s should be reused from the store with its diff", f.Hash)
+		}
+	}
+}
+
+func TestHistoryStoreSharesRepository(t *testing.T) {
+	s := NewHistoryStore()
+	dir := t.TempDir()
+	if s.repository(dir) != s.repository(dir+"/.") {
+		t.Error("the same repository should map to a single history")
+	}
+	var nilStore *HistoryStore
+	if nilStore.repository(dir) == nil {
+		t.Error("a nil store should still return a (transient) history")
+	}
+}
+
+func TestResolveHeadsAndDedup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A feature branch with one unmerged commit, checked out.
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	feature := commitFile(t, path, "b.go", "b\n", "feat: b", "bob", when)
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: head.Name()}); err != nil {
+		t.Fatal(err)
+	}
+
+	backends := []string{BackendGoGit}
+	if _, err := exec.LookPath("git"); err == nil {
+		backends = append(backends, BackendGit)
+	}
+	for _, kind := range backends {
+		backend := newBackend(kind, path, repo)
+		h := newRepoHistory()
+		count := func(o StatsOptions) int {
+			t.Helper()
+			heads, err := resolveHeads(repo, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, err := repoTips(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			commits, err := h.update(heads, refs, backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return len(commits)
+		}
+
+		if n := count(StatsOptions{}); n != 1 {
+			t.Errorf("%s, HEAD only: want 1 commit, got %d", kind, n)
+		}
+		// The first commit is reachable from both branches but counted once.
+		if n := count(StatsOptions{AllBranches: true}); n != 2 {
+			t.Errorf("%s, all branches: want 2 commits, got %d", kind, n)
+		}
+		if n := count(StatsOptions{Refs: []string{"feature", "missing"}}); n != 2 {
+			t.Errorf("%s, explicit ref: want 2 commits, got %d", kind, n)
+		}
+		if n := count(StatsOptions{Refs: []string{feature[:7]}}); n != 2 {
+			t.Errorf("%s, short hash ref: want 2 commits, got %d", kind, n)
+		}
+	}
+	if _, err := resolveHeads(repo, StatsOptions{Refs: []string{"missing"}}); err == nil {
+		t.Error("a selection without any existing ref should be an error")
+	}
+}
+
+func TestRepoHistoryDropsDeletedBranches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	feature := commitFile(t, path, "b.go", "b\n", "feat: b", "bob", when)
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: head.Name()}); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := newBackend(BackendGoGit, path, repo)
+	h := newRepoHistory()
+	update := func(o StatsOptions) {
+		t.Helper()
+		heads, err := resolveHeads(repo, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs, err := repoTips(repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.update(heads, refs, backend); err != nil {
+			t.Fatal(err)
+		}
+	}
+	update(StatsOptions{AllBranches: true})
+	fact := h.commits[feature]
+	if fact == nil {
+		t.Fatal("the feature branch commit should be read")
+	}
+	// A scan of HEAD only keeps the commits of the other branches.
+	update(StatsOptions{})
+	if h.commits[feature] != fact {
+		t.Error("a scan of HEAD only should keep the feature branch commit")
+	}
+
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("feature")); err != nil {
+		t.Fatal(err)
+	}
+	update(StatsOptions{})
+	if h.commits[feature] != nil || len(h.commits) != 1 {
+		t.Errorf("%d commits kept, want the one on HEAD: the deleted branch commit should be dropped", len(h.commits))
+	}
+	if _, ok := h.heads["refs/heads/feature"]; ok {
+		t.Errorf("heads %v still hold the deleted branch", h.heads)
+	}
+}
@@ -0,0 +1,207 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultTopFiles is how many files AggregatedStats.Files lists when the
+// launch options do not say.
+const DefaultTopFiles = 20
+
+// Orders the files of a hotspots report can be sorted by, the largest first
+// (the most recent first for FileSortLastModified).
+const (
+	FileSortCommits      = "commits"
+	FileSortChurn        = "churn"
+	FileSortAdditions    = "additions"
+	FileSortDeletions    = "deletions"
+	FileSortAuthors      = "authors"
+	FileSortLastModified = "lastModified"
+)
+
+// fileKey identifies a file of a scanned repository.
+type fileKey struct {
+	Repo string
+	Path string
+}
+
+// fileEditions is the activity of a single file over the scan window.
+type fileEditions struct {
+	Commits      int
+	Additions    int
+	Deletions    int
+	Authors      map[string]int // alias group key of each identity credited -> lines changed
+	LastModified time.Time
+}
+
+// FileStat is the activity of a single file: how often and how much it
+// changed, and by how many people. JSON-serializable.
+type FileStat struct {
+	Repository   string    `json:"repository"`
+	Path         string    `json:"path"`
+	Commits      int       `json:"commits"`
+	Additions    int       `json:"additions"`
+	Deletions    int       `json:"deletions"`
+	Churn        int       `json:"churn"` // additions + deletions
+	Authors      int       `json:"authors"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// add records a commit credited to ids that changed the file.
+func (f *fileEditions) add(change fileChange, ids []identity, when time.Time) {
+	f.Commits++
+	f.Additions += change.Additions
+	f.Deletions += change.Deletions
+	for _, id := range ids {
+		f.Authors[aliasGroupKey(id.Name, id.Email)] += change.Additions + change.Deletions
+	}
+	if when.After(f.LastModified) {
+		f.LastModified = when
+	}
+}
+
+// merge adds the activity of o into f.
+func (f *fileEditions) merge(o *fileEditions) {
+	f.Commits += o.Commits
+	f.Additions += o.Additions
+	f.Deletions += o.Deletions
+	for author, n := range o.Authors {
+		f.Authors[author] += n
+	}
+	if o.LastModified.After(f.LastModified) {
+		f.LastModified = o.LastModified
+	}
+}
+
+// mergeFileEditions adds every file of src into dst.
+func mergeFileEditions(dst, src map[fileKey]*fileEditions) {
+	for key, f := range src {
+		if dst[key] == nil {
+			dst[key] = &fileEditions{Authors: make(map[string]int, len(f.Authors))}
+		}
+		dst[key].merge(f)
+	}
+}
+
+// fileStats lists the files of an editions map.
+func fileStats(files map[fileKey]*fileEditions) []FileStat {
+	stats := make([]FileStat, 0, len(files))
+	for key, f := range files {
+		stats = append(stats, FileStat{
+			Repository:   key.Repo,
+			Path:         key.Path,
+			Commits:      f.Commits,
+			Additions:    f.Additions,
+			Deletions:    f.Deletions,
+			Churn:        f.Additions + f.Deletions,
+			Authors:      len(f.Authors),
+			LastModified: f.LastModified,
+		})
+	}
+	return stats
+}
+
+// ParseFileSort validates a files order; an empty one selects FileSortCommits.
+func ParseFileSort(by string) (string, error) {
+	switch by {
+	case "":
+		return FileSortCommits, nil
+	case FileSortCommits, FileSortChurn, FileSortAdditions, FileSortDeletions, FileSortAuthors, FileSortLastModified:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown files order %q, use %s", by, strings.Join([]string{
+			FileSortCommits, FileSortChurn, FileSortAdditions, FileSortDeletions, FileSortAuthors, FileSortLastModified,
+		}, ", "))
+	}
+}
+
+// SortFiles orders files by a ParseFileSort order, the largest first. Ties are
+// broken by churn, then path, so the order is stable.
+func SortFiles(files []FileStat, by string) {
+	value := func(f FileStat) int64 {
+		switch by {
+		case FileSortChurn:
+			return int64(f.Churn)
+		case FileSortAdditions:
+			return int64(f.Additions)
+		case FileSortDeletions:
+			return int64(f.Deletions)
+		case FileSortAuthors:
+			return int64(f.Authors)
+		case FileSortLastModified:
+			return f.LastModified.Unix()
+		default:
+			return int64(f.Commits)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if vi, vj := value(files[i]), value(files[j]); vi != vj {
+			return vi > vj
+		}
+		if files[i].Churn != files[j].Churn {
+			return files[i].Churn > files[j].Churn
+		}
+		if files[i].Path != files[j].Path {
+			return files[i].Path < files[j].Path
+		}
+		return files[i].Repository < files[j].Repository
+	})
+}
+
+// FilterFiles keeps the files under the directory prefix (see underPath).
+func FilterFiles(files []FileStat, prefix string) []FileStat {
+	if prefix == "" {
+		return files
+	}
+	var kept []FileStat
+	for _, f := range files {
+		if underPath(f.Path, prefix) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// underPath reports whether path is the path prefix or under it, matching
+// whole path segments only: "web" holds "web/app.js" but not "webui/app.js".
+// A trailing slash of prefix is ignored and an empty prefix holds every path.
+func underPath(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// topFiles keeps the first n files; a negative n keeps them all.
+func topFiles(files []FileStat, n int) []FileStat {
+	if n >= 0 && len(files) > n {
+		return files[:n]
+	}
+	return files
+}
+
+// PrintFiles prints a hotspots table, with the repository column when the
+// files come from several repositories.
+func PrintFiles(files []FileStat) {
+	repos := map[string]bool{}
+	for _, f := range files {
+		repos[f.Repository] = true
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "COMMITS\tCHURN\t+\t-\tAUTHORS\tLAST MODIFIED\t"
+	if len(repos) > 1 {
+		header += "REPOSITORY\t"
+	}
+	fmt.Fprintln(w, header+"PATH")
+	for _, f := range files {
+		line := fmt.Sprintf("%d\t%d\t%d\t%d\t%d\t%s\t", f.Commits, f.Churn, f.Additions, f.Deletions, f.Authors, f.LastModified.Format(dateLayout))
+		if len(repos) > 1 {
+			line += f.Repository + "\t"
+		}
+		fmt.Fprintln(w, line+f.Path)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSortAndFilterFiles(t *testing.T) {
+	day := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	files := []FileStat{
+		{Path: "web/app.js", Commits: 2, Churn: 50, Authors: 1, LastModified: day},
+		{Path: "services/billing/a.go", Commits: 5, Churn: 10, Authors: 3, LastModified: day.AddDate(0, 0, -1)},
+		{Path: "services/billing/b.go", Commits: 5, Churn: 20, Authors: 2, LastModified: day.AddDate(0, 0, 1)},
+	}
+	paths := func(files []FileStat) []string {
+		var out []string
+		for _, f := range files {
+			out = append(out, f.Path)
+		}
+		return out
+	}
+	for by, want := range map[string]string{
+		FileSortCommits:      "services/billing/b.go", // ties broken by churn
+		FileSortChurn:        "web/app.js",
+		FileSortAuthors:      "services/billing/a.go",
+		FileSortLastModified: "services/billing/b.go",
+	} {
+		SortFiles(files, by)
+		if files[0].Path != want {
+			t.Errorf("sorted by %s: %v, want %s first", by, paths(files), want)
+		}
+	}
+	if got := FilterFiles(files, "services/"); len(got) != 2 {
+		t.Errorf("FilterFiles(services/) = %v, want the two billing files", paths(got))
+	}
+	if got := FilterFiles(files, "services/bill"); len(got) != 0 {
+		t.Errorf("FilterFiles(services/bill) = %v, want none: bill is not a directory", paths(got))
+	}
+	if _, err := ParseFileSort("size"); err == nil {
+		t.Error("an unknown order should be an error")
+	}
+}
+
+func TestLaunchFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-3 * time.Hour)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+	commitFile(t, path, "a.go", "a\nb\nc\n", "feat: more a", "bob", when.Add(time.Hour))
+	commitFile(t, path, "b.go", "b\n", "feat: b", "alice", when.Add(2*time.Hour))
+
+	agg := Aggregate(Launch(LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true}))
+	if len(agg.Files) != 2 {
+		t.Fatalf("Files = %+v, want a.go and b.go", agg.Files)
+	}
+	a := agg.Files[0]
+	if a.Path != "a.go" || a.Commits != 2 || a.Additions != 3 || a.Authors != 2 || a.Repository != path {
+		t.Errorf("a.go = %+v, want 2 commits, 3 additions by 2 authors", a)
+	}
+	if !a.LastModified.Equal(when.Add(time.Hour).Truncate(time.Second)) {
+		t.Errorf("a.go last modified %s, want %s", a.LastModified, when.Add(time.Hour))
+	}
+
+	agg = Aggregate(Launch(LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, TopFiles: 1}))
+	if len(agg.Files) != 1 {
+		t.Errorf("TopFiles 1 lists %d files", len(agg.Files))
+	}
+}
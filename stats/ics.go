@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsLineLength is the longest content line, in octets, before it is folded.
+const icsLineLength = 75
+
+// PerContributorEvents returns the launch options whose statistics WriteICS
+// needs for one event per contributor: the days of every contributor.
+func PerContributorEvents(opts LaunchOptions) LaunchOptions {
+	opts.ContributorDays = true
+	return opts
+}
+
+// WriteICS writes the days with commits of the calendar as an iCalendar file
+// of all-day events, summarized with their commit count and lines changed.
+// With perContributor, there is an event per contributor and day instead,
+// from the contributor days (see PerContributorEvents). now stamps the events.
+func WriteICS(w io.Writer, agg AggregatedStats, perContributor bool, now time.Time) error {
+	name := "gitcontrib"
+	if agg.User != "" && agg.User != "all" {
+		name += ": " + agg.User
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	icsLine(&b, "BEGIN:VCALENDAR")
+	icsLine(&b, "VERSION:2.0")
+	icsLine(&b, "PRODID:-//gitcontrib//gitcontrib//EN")
+	icsLine(&b, "CALSCALE:GREGORIAN")
+	icsLine(&b, "X-WR-CALNAME:"+icsEscape(name))
+	if perContributor {
+		for _, c := range agg.ContributorDays {
+			h := fnv.New32a()
+			h.Write([]byte(c.Author))
+			for _, d := range c.Days {
+				icsEvent(&b, d, fmt.Sprintf("-%08x", h.Sum32()), c.Author+": ", stamp)
+			}
+		}
+	} else {
+		for _, d := range agg.Calendar {
+			if d.Count > 0 {
+				icsEvent(&b, d, "", "", stamp)
+			}
+		}
+	}
+	icsLine(&b, "END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsEvent writes the all-day event of a day, its UID made unique by uid and
+// its summary prefixed by prefix.
+func icsEvent(b *strings.Builder, d DayCount, uid, prefix, stamp string) {
+	day, err := time.Parse(dateLayout, d.Date)
+	if err != nil {
+		return
+	}
+	icsLine(b, "BEGIN:VEVENT")
+	icsLine(b, "UID:"+day.Format("20060102")+uid+"@gitcontrib")
+	icsLine(b, "DTSTAMP:"+stamp)
+	icsLine(b, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+	icsLine(b, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+	icsLine(b, "SUMMARY:"+icsEscape(fmt.Sprintf("%s%s, +%d -%d", prefix, plural(d.Count, "commit"), d.Additions, d.Deletions)))
+	icsLine(b, "TRANSP:TRANSPARENT")
+	icsLine(b, "END:VEVENT")
+}
+
+// icsLine writes a content line ended by CRLF, folded into lines of at most
+// icsLineLength octets, without splitting a UTF-8 character.
+func icsLine(b *strings.Builder, line string) {
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts.
+		limit = icsLineLength - 1
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// icsEscape escapes a TEXT value: backslashes, semicolons, commas and newlines.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
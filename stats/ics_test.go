@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 30, 0, 0, time.UTC)
+	agg := AggregatedStats{
+		Calendar: []DayCount{
+			{Date: "2026-03-02", Count: 0, Weekday: 1},
+			{Date: "2026-03-03", Count: 3, Additions: 12, Deletions: 4, Weekday: 2},
+		},
+		ContributorDays: []ContributorDays{{Author: "Doe, Jane; " + strings.Repeat("é", 40), Days: []DayCount{{Date: "2026-03-03", Count: 1, Additions: 2}}}},
+	}
+
+	var b strings.Builder
+	if err := WriteICS(&b, agg, false, now); err != nil {
+		t.Fatal(err)
+	}
+	want := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//gitcontrib//gitcontrib//EN\r\nCALSCALE:GREGORIAN\r\nX-WR-CALNAME:gitcontrib\r\n" +
+		"BEGIN:VEVENT\r\nUID:20260303@gitcontrib\r\nDTSTAMP:20260305T123000Z\r\nDTSTART;VALUE=DATE:20260303\r\nDTEND;VALUE=DATE:20260304\r\n" +
+		"SUMMARY:3 commits\\, +12 -4\r\nTRANSP:TRANSPARENT\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if b.String() != want {
+		t.Errorf("calendar:\n%q\nwant:\n%q", b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteICS(&b, agg, true, now); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Count(out, "BEGIN:VEVENT") != 1 || !strings.Contains(out, "SUMMARY:Doe\\, Jane\\; éé") {
+		t.Errorf("per-contributor calendar:\n%s", out)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:Doe\\, Jane\\; "+strings.Repeat("é", 40)+": 1 commit\\, +2 -0\r\n") {
+		t.Errorf("the summary should unfold to the contributor and counts:\n%s", unfolded)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > icsLineLength {
+			t.Errorf("line of %d octets: %s", len(line), line)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the statistics of the results, aggregated, as an indented
+// JSON object: the AggregatedStats schema of /api/stats, without the cache
+// metadata and parameters.
+func WriteJSON(w io.Writer, results []*StatsResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Aggregate(results))
+}
+
+// ndjsonResult is a line of WriteNDJSON: the statistics of a result, with the
+// folders it scanned and why it failed, if it did.
+type ndjsonResult struct {
+	Folder string `json:"folder"`
+	Error  string `json:"error,omitempty"`
+	AggregatedStats
+}
+
+// WriteNDJSON writes the statistics of each result as a JSON object per line
+// (newline-delimited JSON): one per repository, or a single one when the
+// results are merged.
+func WriteNDJSON(w io.Writer, results []*StatsResult) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		line := ndjsonResult{Folder: strings.Join(r.Options.Folders, ","), AggregatedStats: Aggregate([]*StatsResult{r})}
+		if r.Error != nil {
+			line.Error = r.Error.Error()
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
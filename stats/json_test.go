@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	repoA, repoB := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	initRepo(t, repoA)
+	initRepo(t, repoB)
+	commitFile(t, repoA, "a.go", "a\n", "feat: a", "alice", now.AddDate(0, 0, -2))
+	commitFile(t, repoB, "b.go", "b\n", "fix: b", "bob", now.AddDate(0, 0, -2))
+	commitFile(t, repoB, "b.go", "b\nb\n", "fix: b", "bob", now.AddDate(0, 0, -1))
+
+	missing := filepath.Join(dir, "missing")
+	opts := LaunchOptions{Since: now.AddDate(0, 0, -6).Format(dateLayout), Until: now.Format(dateLayout), Folders: []string{repoA, repoB, missing}, Dashboard: true}
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, Launch(opts)); err != nil {
+		t.Fatal(err)
+	}
+	var lines []ndjsonResult
+	for s := bufio.NewScanner(&buf); s.Scan(); {
+		var line ndjsonResult
+		if err := json.Unmarshal(s.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %s: %v", s.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 3 || lines[0].Folder != repoA || lines[0].TotalCommits != 1 || lines[1].Folder != repoB || lines[1].TotalCommits != 2 {
+		t.Fatalf("lines %+v, want a then b with 1 and 2 commits", lines)
+	}
+	if lines[0].Error != "" || lines[2].Folder != missing || lines[2].Error == "" || lines[2].Errors != 1 {
+		t.Errorf("lines %+v, want the error of the missing folder only", lines)
+	}
+	opts.Folders = opts.Folders[:2]
+
+	opts.Merge = true
+	buf.Reset()
+	if err := WriteJSON(&buf, Launch(opts)); err != nil {
+		t.Fatal(err)
+	}
+	var agg AggregatedStats
+	if err := json.Unmarshal(buf.Bytes(), &agg); err != nil {
+		t.Fatal(err)
+	}
+	if agg.TotalCommits != 3 || len(agg.Contributors) != 2 {
+		t.Errorf("merged %d commits by %d contributors, want 3 by 2", agg.TotalCommits, len(agg.Contributors))
+	}
+}
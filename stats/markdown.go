@@ -0,0 +1,226 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Markdown heatmap cells, per activity level.
+var markdownLevelCells = [...]rune{
+	levelEmpty:  '·',
+	levelLow:    '░',
+	levelMiddle: '▓',
+	levelHigh:   '█',
+}
+
+// WriteMarkdown writes a summary of the aggregated statistics as Markdown, for
+// pull requests and release notes: the highlights, a Unicode heatmap and the
+// contributors, languages and commit types tables. It only depends on the
+// statistics (not on the current time), so two summaries of the same window
+// can be diffed.
+func WriteMarkdown(w io.Writer, agg AggregatedStats) error {
+	var b strings.Builder
+	title := "Contributions"
+	if agg.User != "" && agg.User != "all" {
+		title += " of " + markdownEscape(agg.User)
+	}
+	fmt.Fprintf(&b, "## %s\n\n", title)
+	fmt.Fprintf(&b, "%s → %s (%s", agg.BeginOfScan.Format(dateLayout), agg.EndOfScan.Format(dateLayout), plural(agg.DurationInDays, "day"))
+	if agg.AnalyzedRepos > 0 {
+		fmt.Fprintf(&b, ", %s", plural(agg.AnalyzedRepos, "repository"))
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("### Highlights\n\n")
+	for _, h := range markdownHighlights(agg) {
+		fmt.Fprintf(&b, "- **%s:** %s\n", h[0], h[1])
+	}
+
+	b.WriteString("\n### Activity\n\n```text\n")
+	b.WriteString(markdownHeatmap(agg.Calendar))
+	b.WriteString("```\n")
+
+	b.WriteString("\n### Contributors\n\n")
+	rows := make([][]string, 0, len(agg.Contributors))
+	for _, c := range agg.Contributors {
+		rows = append(rows, []string{markdownEscape(c.Author), itoa(c.Commits), "+" + itoa(c.Additions), "-" + itoa(c.Deletions), itoa(c.Total)})
+	}
+	markdownTable(&b, []string{"Contributor", "Commits", "Additions", "Deletions", "Total"}, rows)
+
+	b.WriteString("\n### Languages\n\n")
+	lines := 0
+	for _, l := range agg.Languages {
+		lines += l.Total
+	}
+	rows = rows[:0]
+	for _, l := range agg.Languages {
+		rows = append(rows, []string{markdownEscape(l.Name), "+" + itoa(l.Additions), "-" + itoa(l.Deletions), itoa(l.Total), percent(l.Total, lines)})
+	}
+	markdownTable(&b, []string{"Language", "Additions", "Deletions", "Total", "Share"}, rows)
+
+	b.WriteString("\n### Commit types\n\n")
+	commits := 0
+	for _, t := range agg.CommitTypes {
+		commits += t.Count
+	}
+	rows = rows[:0]
+	for _, t := range agg.CommitTypes {
+		rows = append(rows, []string{markdownEscape(t.Type), itoa(t.Count), percent(t.Count, commits)})
+	}
+	markdownTable(&b, []string{"Type", "Commits", "Share"}, rows)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownHighlights are the at-a-glance facts of the web UI highlights that
+// do not depend on the current day, as label and value pairs.
+func markdownHighlights(agg AggregatedStats) [][2]string {
+	additions, deletions := calendarLines(agg)
+	commits := itoa(agg.TotalCommits)
+	if agg.MergeCommits > 0 {
+		commits += fmt.Sprintf(" (%s)", plural(agg.MergeCommits, "merge"))
+	}
+	contributors := itoa(len(agg.Contributors))
+	if len(agg.NewContributors) > 0 {
+		contributors += fmt.Sprintf(" (%d new)", len(agg.NewContributors))
+	}
+	highlights := [][2]string{
+		{"Commits", commits},
+		{"Contributors", contributors},
+		{"Lines", fmt.Sprintf("+%d / -%d", additions, deletions)},
+	}
+
+	longest, run := 0, 0
+	var busiest *DayCount
+	for i, d := range agg.Calendar {
+		if d.Count > 0 {
+			run++
+		} else {
+			run = 0
+		}
+		longest = max(longest, run)
+		if d.Count > 0 && (busiest == nil || d.Count > busiest.Count) {
+			busiest = &agg.Calendar[i]
+		}
+	}
+	highlights = append(highlights, [2]string{"Longest streak", plural(longest, "day")})
+	if day := argmax(agg.CommitsByWeekday[:]); agg.CommitsByWeekday[day] > 0 {
+		// CommitsByWeekday is Monday-first.
+		highlights = append(highlights, [2]string{"Most active day", time.Weekday((day + 1) % 7).String()})
+	}
+	if hour := argmax(agg.CommitsByHour[:]); agg.CommitsByHour[hour] > 0 {
+		highlights = append(highlights, [2]string{"Peak hour", fmt.Sprintf("%02d:00", hour)})
+	}
+	if busiest != nil {
+		highlights = append(highlights, [2]string{"Busiest day", fmt.Sprintf("%s (%s)", busiest.Date, plural(busiest.Count, "commit"))})
+	}
+	if len(agg.Languages) > 0 && agg.Languages[0].Total > 0 {
+		total := 0
+		for _, l := range agg.Languages {
+			total += l.Total
+		}
+		highlights = append(highlights, [2]string{"Top language", fmt.Sprintf("%s (%s)", markdownEscape(agg.Languages[0].Name), percent(agg.Languages[0].Total, total))})
+	}
+	lines := 0
+	for _, c := range agg.Contributors {
+		lines += c.Total
+	}
+	if len(agg.Contributors) > 0 && lines > 0 {
+		top := agg.Contributors[0]
+		highlights = append(highlights, [2]string{"Top contributor", fmt.Sprintf("%s (%s of the lines)", markdownEscape(top.Author), percent(top.Total, lines))})
+	}
+	return highlights
+}
+
+// markdownHeatmap draws the calendar like the terminal heatmap, with a
+// character per day: a column per week, a row per day from the first day of
+// the window, and the month names above.
+func markdownHeatmap(calendar []DayCount) string {
+	weeks := (len(calendar) + 6) / 7
+	months := []rune(strings.Repeat(" ", weeks+3)) // the last name may overflow
+	month, free := "", 0
+	for col := 0; col < weeks; col++ {
+		d := calendar[col*7]
+		if d.Date[:7] == month {
+			continue
+		}
+		if (month != "" || d.Date[8:] == "01") && col >= free {
+			date, _ := time.Parse(dateLayout, d.Date)
+			copy(months[col:], []rune(date.Month().String()[:3]))
+			free = col + 4
+		}
+		month = d.Date[:7]
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight("   "+string(months), " ") + "\n")
+	for row := 0; row < 7 && row < len(calendar); row++ {
+		b.WriteString(time.Weekday(calendar[row].Weekday).String()[:2] + " ")
+		for i := row; i < len(calendar); i += 7 {
+			b.WriteRune(markdownLevelCells[cellLevel(calendar[i].Count)])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("   Less")
+	for _, c := range markdownLevelCells {
+		b.WriteString(" " + string(c))
+	}
+	b.WriteString(" More\n")
+	return b.String()
+}
+
+// markdownTable writes a table, its numeric columns (all but the first)
+// right-aligned, or a placeholder when there are no rows.
+func markdownTable(b *strings.Builder, header []string, rows [][]string) {
+	if len(rows) == 0 {
+		b.WriteString("_None._\n")
+		return
+	}
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n|")
+	for i := range header {
+		if i == 0 {
+			b.WriteString(" --- |")
+		} else {
+			b.WriteString(" ---: |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
+
+// markdownEscape escapes the characters that Markdown would interpret in a
+// name, including the table separator.
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ReplaceAll(s, "\n", " ") {
+		if strings.ContainsRune("\\`*_[]<>|#", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// percent formats part as a rounded percentage of total.
+func percent(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", (part*100+total/2)/total)
+}
+
+// argmax is the index of the largest value, the first one on ties.
+func argmax(values []int) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
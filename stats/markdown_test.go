@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	agg := AggregatedStats{
+		User:           "all",
+		BeginOfScan:    time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC),
+		EndOfScan:      time.Date(2026, 3, 8, 23, 59, 59, 0, time.UTC),
+		DurationInDays: 14,
+		AnalyzedRepos:  1,
+		TotalCommits:   13,
+		Contributors:   []Contributor{{Author: "alice_b", Commits: 12, Additions: 30, Deletions: 10, Total: 40}, {Author: "bob|c", Commits: 1, Additions: 10, Total: 10}},
+		Languages:      []Language{{Name: "Go", Additions: 30, Deletions: 10, Total: 40}, {Name: "C++", Additions: 10, Total: 10}},
+		CommitTypes:    []CommitTypeCount{{Type: "feat", Count: 9}, {Type: "fix", Count: 4}},
+	}
+	agg.CommitsByWeekday[2] = 13
+	agg.CommitsByHour[9] = 13
+	for i := 0; i < 14; i++ {
+		day := agg.BeginOfScan.AddDate(0, 0, i)
+		agg.Calendar = append(agg.Calendar, DayCount{Date: day.Format(dateLayout), Weekday: int(day.Weekday())})
+	}
+	agg.Calendar[1].Count, agg.Calendar[1].Additions = 1, 10
+	agg.Calendar[2].Count, agg.Calendar[2].Additions, agg.Calendar[2].Deletions = 12, 30, 10
+
+	var b strings.Builder
+	if err := WriteMarkdown(&b, agg); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"## Contributions\n\n2026-02-23 → 2026-03-08 (14 days, 1 repository)\n",
+		"- **Commits:** 13\n",
+		"- **Lines:** +40 / -10\n",
+		"- **Longest streak:** 2 days\n",
+		"- **Most active day:** Wednesday\n",
+		"- **Peak hour:** 09:00\n",
+		"- **Busiest day:** 2026-02-25 (12 commits)\n",
+		"- **Top language:** Go (80%)\n",
+		"- **Top contributor:** alice\\_b (80% of the lines)\n",
+		"    Mar\nMo ··\nTu ░·\nWe █·\n",
+		"| bob\\|c | 1 | +10 | -0 | 10 |\n",
+		"| C++ | +10 | -0 | 10 | 20% |\n",
+		"| feat | 9 | 69% |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary misses %q:\n%s", want, out)
+		}
+	}
+
+	var again strings.Builder
+	if err := WriteMarkdown(&again, agg); err != nil || again.String() != out {
+		t.Error("the summary should be stable")
+	}
+}
@@ -0,0 +1,40 @@
+package stats
+
+import "fmt"
+
+// Policies for merge commits.
+const (
+	MergesInclude = "include" // count merges like any other commit (the default)
+	MergesExclude = "exclude" // leave merges out
+	MergesOnly    = "only"    // count merges alone
+)
+
+// ParseMerges validates a merge commit policy; an empty policy selects the
+// default.
+func ParseMerges(policy string) (string, error) {
+	switch policy {
+	case "":
+		return MergesInclude, nil
+	case MergesInclude, MergesExclude, MergesOnly:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown merges policy %q, use %s, %s or %s", policy, MergesInclude, MergesExclude, MergesOnly)
+	}
+}
+
+// isMerge reports whether a commit has several parents.
+func (c *commitFact) isMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// keepCommit reports whether a commit is counted under a merge policy.
+func keepCommit(c *commitFact, policy string) bool {
+	switch policy {
+	case MergesExclude:
+		return !c.isMerge()
+	case MergesOnly:
+		return c.isMerge()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseMerges(t *testing.T) {
+	for policy, want := range map[string]string{"": MergesInclude, "include": MergesInclude, "exclude": MergesExclude, "only": MergesOnly} {
+		got, err := ParseMerges(policy)
+		if err != nil || got != want {
+			t.Errorf("ParseMerges(%q) = (%q, %v), want %q", policy, got, err, want)
+		}
+	}
+	if _, err := ParseMerges("squash"); err == nil {
+		t.Error("an unknown policy should be an error")
+	}
+}
+
+func TestLaunchMergesPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	when := time.Now().Add(-2 * time.Hour)
+	base := commitFile(t, path, "a.go", "a\n", "feat: a", "alice", when)
+	main := commitFile(t, path, "b.go", "b\n", "feat: b", "alice", when.Add(time.Minute))
+
+	// A merge of main with base that also adds two lines: only those count,
+	// not b.go that base lacks.
+	sig := &object.Signature{Name: "bob", Email: "bob@example.com", When: when.Add(2 * time.Minute)}
+	commitAs(t, path, "c.go", "c\nc\n", "Merge branch", sig, sig, plumbing.NewHash(main), plumbing.NewHash(base))
+
+	for _, backend := range []string{BackendGoGit, BackendGit} {
+		launch := func(policy string) AggregatedStats {
+			return Aggregate(Launch(LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, Backend: backend, Merges: policy}))
+		}
+		for policy, want := range map[string][3]int{
+			MergesInclude: {3, 1, 2},
+			MergesExclude: {2, 0, 2},
+			MergesOnly:    {1, 1, 0},
+		} {
+			agg := launch(policy)
+			if got := [3]int{agg.TotalCommits, agg.MergeCommits, agg.NonMergeCommits}; got != want {
+				t.Errorf("%s, merges=%s: (total, merges, non-merges) = %v, want %v", backend, policy, got, want)
+			}
+		}
+		additions := 0
+		for _, d := range launch(MergesOnly).Calendar {
+			additions += d.Additions
+		}
+		if additions != 2 {
+			t.Errorf("%s: the merge added %d lines, want 2 (against its first parent)", backend, additions)
+		}
+	}
+}
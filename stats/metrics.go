@@ -0,0 +1,193 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMetricSet is the name /metrics gives the default parameter set.
+const DefaultMetricSet = "default"
+
+// maxMetricContributors caps the contributors exposed one by one per
+// parameter set; the others are summed as otherContributor.
+const (
+	maxMetricContributors = 50
+	otherContributor      = "_other"
+)
+
+// metricSet is a parameter set exposed on /metrics: its cached statistics,
+// nil until its first scan is done, and its scan counters.
+type metricSet struct {
+	Name      string
+	Stats     *AggregatedStats
+	UpdatedAt time.Time
+	Scans     scanStats
+}
+
+// metricFamily is a metric and its samples, in the Prometheus text format.
+type metricFamily struct {
+	name, help, kind string
+	samples          []metricSample
+}
+
+type metricSample struct {
+	labels []string // name, value pairs
+	value  float64
+}
+
+func (f *metricFamily) add(value float64, labels ...string) {
+	f.samples = append(f.samples, metricSample{labels: labels, value: value})
+}
+
+// writeMetrics writes the gauges of the statistics and the scan counters of
+// every parameter set in the Prometheus text exposition format, each sample
+// labelled with its set.
+func writeMetrics(w io.Writer, sets []metricSet, now time.Time) error {
+	gauge := func(name, help string) *metricFamily {
+		return &metricFamily{name: "gitcontrib_" + name, help: help, kind: "gauge"}
+	}
+	counter := func(name, help string) *metricFamily {
+		return &metricFamily{name: "gitcontrib_" + name, help: help, kind: "counter"}
+	}
+	var (
+		commits              = gauge("commits", "Commits in the scan window.")
+		contributors         = gauge("contributors", "Contributors in the scan window.")
+		repoCommits          = gauge("repository_commits", "Commits per repository.")
+		repoAdditions        = gauge("repository_additions", "Lines added per repository.")
+		repoDeletions        = gauge("repository_deletions", "Lines deleted per repository.")
+		contributorCommits   = gauge("contributor_commits", "Commits per contributor, the least active summed as "+otherContributor+".")
+		contributorAdditions = gauge("contributor_additions", "Lines added per contributor.")
+		contributorDeletions = gauge("contributor_deletions", "Lines deleted per contributor.")
+		langAdditions        = gauge("language_additions", "Lines added per language.")
+		langDeletions        = gauge("language_deletions", "Lines deleted per language.")
+		typeCommits          = gauge("commit_type_commits", "Commits per Conventional Commits type.")
+		scanDuration         = gauge("scan_duration_seconds", "Duration of the last scan.")
+		cacheAge             = gauge("cache_age_seconds", "Age of the cached statistics.")
+		scans                = counter("scans_total", "Scans since the server started.")
+		failures             = counter("refresh_failures_total", "Scans where a repository could not be scanned.")
+	)
+
+	for _, set := range sets {
+		scans.add(float64(set.Scans.Scans), "set", set.Name)
+		failures.add(float64(set.Scans.Failures), "set", set.Name)
+		if set.Scans.Scans > 0 {
+			scanDuration.add(set.Scans.Duration.Seconds(), "set", set.Name)
+		}
+		agg := set.Stats
+		if agg == nil {
+			continue
+		}
+		cacheAge.add(now.Sub(set.UpdatedAt).Seconds(), "set", set.Name)
+		commits.add(float64(agg.TotalCommits), "set", set.Name)
+		contributors.add(float64(len(agg.Contributors)), "set", set.Name)
+		for _, r := range agg.Repositories {
+			repoCommits.add(float64(r.Commits), "set", set.Name, "repository", r.Folder)
+			repoAdditions.add(float64(r.Additions), "set", set.Name, "repository", r.Folder)
+			repoDeletions.add(float64(r.Deletions), "set", set.Name, "repository", r.Folder)
+		}
+		var other Contributor
+		for i, c := range agg.Contributors {
+			if i >= maxMetricContributors {
+				other.Commits += c.Commits
+				other.Additions += c.Additions
+				other.Deletions += c.Deletions
+				continue
+			}
+			id := contributorIdentity(c)
+			contributorCommits.add(float64(c.Commits), "set", set.Name, "contributor", c.Author, "identity", id)
+			contributorAdditions.add(float64(c.Additions), "set", set.Name, "contributor", c.Author, "identity", id)
+			contributorDeletions.add(float64(c.Deletions), "set", set.Name, "contributor", c.Author, "identity", id)
+		}
+		if len(agg.Contributors) > maxMetricContributors {
+			contributorCommits.add(float64(other.Commits), "set", set.Name, "contributor", otherContributor, "identity", otherContributor)
+			contributorAdditions.add(float64(other.Additions), "set", set.Name, "contributor", otherContributor, "identity", otherContributor)
+			contributorDeletions.add(float64(other.Deletions), "set", set.Name, "contributor", otherContributor, "identity", otherContributor)
+		}
+		for _, l := range agg.Languages {
+			langAdditions.add(float64(l.Additions), "set", set.Name, "language", l.Name)
+			langDeletions.add(float64(l.Deletions), "set", set.Name, "language", l.Name)
+		}
+		for _, t := range agg.CommitTypes {
+			typeCommits.add(float64(t.Count), "set", set.Name, "type", t.Type)
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range []*metricFamily{
+		commits, contributors, repoCommits, repoAdditions, repoDeletions,
+		contributorCommits, contributorAdditions, contributorDeletions,
+		langAdditions, langDeletions, typeCommits,
+		scanDuration, cacheAge, scans, failures,
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			if len(s.labels) > 0 {
+				b.WriteByte('{')
+				for i := 0; i < len(s.labels); i += 2 {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(&b, `%s="%s"`, s.labels[i], escapeLabel(s.labels[i+1]))
+				}
+				b.WriteByte('}')
+			}
+			fmt.Fprintf(&b, " %s\n", strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// contributorIdentity labels the samples of a contributor: display names are
+// not unique, but the emails (or the names, without an email) contributors
+// are grouped by are.
+func contributorIdentity(c Contributor) string {
+	if len(c.Identities) == 0 {
+		return c.Author
+	}
+	return strings.ToLower(c.Identities[0])
+}
+
+// escapeLabel escapes a label value: backslashes, double quotes and newlines.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// metricSets returns the parameter sets /metrics exposes: the default one,
+// then the named ones by name. A set whose statistics are missing or stale is
+// refreshed in the background, so a scrape never waits for a scan.
+func (c *statsCache) metricSets(named map[string]LaunchOptions) []metricSet {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := append([]LaunchOptions{c.baseOpts}, make([]LaunchOptions, len(names))...)
+	for i, name := range names {
+		all[i+1] = named[name]
+	}
+	names = append([]string{DefaultMetricSet}, names...)
+
+	sets := make([]metricSet, len(all))
+	for i, opts := range all {
+		key := cacheKey(opts)
+		entry, stale, _ := c.state(key)
+		if stale {
+			c.refreshInBackground(key, opts)
+		}
+		c.mu.RLock()
+		sets[i] = metricSet{Name: names[i], Scans: c.scans[key]}
+		c.mu.RUnlock()
+		if entry != nil {
+			sets[i].Stats = &entry.Stats
+			sets[i].UpdatedAt = entry.UpdatedAt
+		}
+	}
+	return sets
+}
@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	agg := AggregatedStats{
+		TotalCommits: 3,
+		Repositories: []RepositoryStat{{Folder: `C:\src\"app"`, Commits: 3, Additions: 10, Deletions: 2}},
+		Languages:    []Language{{Name: "Go", Additions: 10, Deletions: 2, Total: 12}},
+		CommitTypes:  []CommitTypeCount{{Type: "feat", Count: 2}},
+	}
+	// Namesakes stay apart, by their email.
+	agg.Contributors = append(agg.Contributors,
+		Contributor{Author: "Sam", Identities: []string{"Sam@a.example"}, Commits: 2},
+		Contributor{Author: "Sam", Identities: []string{"sam@b.example"}, Commits: 1})
+	for i := 0; i < maxMetricContributors; i++ {
+		agg.Contributors = append(agg.Contributors, Contributor{Author: fmt.Sprintf("dev%02d", i), Commits: 1, Additions: 2})
+	}
+	sets := []metricSet{
+		{Name: DefaultMetricSet, Stats: &agg, UpdatedAt: now.Add(-90 * time.Second), Scans: scanStats{Scans: 2, Failures: 1, Duration: 1500 * time.Millisecond}},
+		{Name: "pending"},
+	}
+	var b strings.Builder
+	if err := writeMetrics(&b, sets, now); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE gitcontrib_commits gauge\ngitcontrib_commits{set=\"default\"} 3\n",
+		`gitcontrib_repository_additions{set="default",repository="C:\\src\\\"app\""} 10`,
+		`gitcontrib_contributor_commits{set="default",contributor="Sam",identity="sam@a.example"} 2`,
+		`gitcontrib_contributor_commits{set="default",contributor="Sam",identity="sam@b.example"} 1`,
+		`gitcontrib_contributor_commits{set="default",contributor="dev47",identity="dev47"} 1`,
+		`gitcontrib_contributor_commits{set="default",contributor="_other",identity="_other"} 2`,
+		`gitcontrib_contributor_additions{set="default",contributor="_other",identity="_other"} 4`,
+		`gitcontrib_language_deletions{set="default",language="Go"} 2`,
+		`gitcontrib_commit_type_commits{set="default",type="feat"} 2`,
+		`gitcontrib_scan_duration_seconds{set="default"} 1.5`,
+		`gitcontrib_cache_age_seconds{set="default"} 90`,
+		"# TYPE gitcontrib_scans_total counter\ngitcontrib_scans_total{set=\"default\"} 2\ngitcontrib_scans_total{set=\"pending\"} 0\n",
+		`gitcontrib_refresh_failures_total{set="default"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics miss %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `contributor="dev48"`) {
+		t.Error("contributors past the cap should be summed as _other")
+	}
+	if strings.Contains(out, `gitcontrib_commits{set="pending"}`) {
+		t.Error("a set without statistics should only expose its scan counters")
+	}
+}
@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultInactiveDays is how many days without a commit make a contributor
+// inactive when the launch options do not say.
+const DefaultInactiveDays = 90
+
+// DefaultOwnershipDepth is how many directory levels an ownership report
+// covers by default.
+const DefaultOwnershipDepth = 2
+
+// maxOwners is how many owners a directory lists.
+const maxOwners = 10
+
+// Owner is a contributor's share of the changes made to a directory.
+type Owner struct {
+	Author     string    `json:"author"`
+	Changes    int       `json:"changes"`    // lines added and deleted
+	Share      float64   `json:"share"`      // of the directory's changes, 0..1
+	LastCommit time.Time `json:"lastCommit"` // their last commit in the whole history, up to the end of the window
+}
+
+// DirectoryOwnership tells who changed a directory (its files and
+// subdirectories) and how much the directory depends on few people.
+type DirectoryOwnership struct {
+	Repository string `json:"repository"`
+	Path       string `json:"path"` // "." for the repository root
+	Changes    int    `json:"changes"`
+	Authors    int    `json:"authors"`
+	// BusFactor is the minimum number of authors covering half the changes.
+	BusFactor int     `json:"busFactor"`
+	Owners    []Owner `json:"owners"` // the largest share first
+	// TopOwnerInactive is set when the top owner made no commit in the
+	// InactiveDays before the end of the scan window.
+	TopOwnerInactive bool `json:"topOwnerInactive"`
+}
+
+// buildOwnership rolls the per-file changes of every author up into the
+// directories of at most depth levels (all of them when depth is negative).
+// names maps alias group keys to contributor names and lastCommits to their
+// last commit; an owner whose last commit is more than inactiveDays before end
+// is inactive.
+func buildOwnership(files map[fileKey]*fileEditions, names map[string]string, lastCommits map[string]time.Time, depth, inactiveDays int, end time.Time) []DirectoryOwnership {
+	dirs := map[fileKey]map[string]int{} // directory -> author -> changes
+	for key, f := range files {
+		for _, dir := range ancestors(key.Path, depth) {
+			dirKey := fileKey{Repo: key.Repo, Path: dir}
+			if dirs[dirKey] == nil {
+				dirs[dirKey] = map[string]int{}
+			}
+			for author, n := range f.Authors {
+				dirs[dirKey][author] += n
+			}
+		}
+	}
+
+	inactiveSince := end.AddDate(0, 0, -inactiveDays)
+	ownership := make([]DirectoryOwnership, 0, len(dirs))
+	for key, authors := range dirs {
+		d := DirectoryOwnership{Repository: key.Repo, Path: key.Path, Authors: len(authors)}
+		for author, n := range authors {
+			d.Changes += n
+			name := names[author]
+			if name == "" {
+				name = author[strings.Index(author, ":")+1:]
+			}
+			d.Owners = append(d.Owners, Owner{Author: name, Changes: n, LastCommit: lastCommits[author]})
+		}
+		if d.Changes == 0 {
+			continue
+		}
+		sort.Slice(d.Owners, func(i, j int) bool {
+			if d.Owners[i].Changes != d.Owners[j].Changes {
+				return d.Owners[i].Changes > d.Owners[j].Changes
+			}
+			return d.Owners[i].Author < d.Owners[j].Author
+		})
+		covered := 0
+		for i := range d.Owners {
+			d.Owners[i].Share = float64(d.Owners[i].Changes) / float64(d.Changes)
+			if 2*covered < d.Changes {
+				covered += d.Owners[i].Changes
+				d.BusFactor++
+			}
+		}
+		d.TopOwnerInactive = d.Owners[0].LastCommit.Before(inactiveSince)
+		if len(d.Owners) > maxOwners {
+			d.Owners = d.Owners[:maxOwners]
+		}
+		ownership = append(ownership, d)
+	}
+	sort.Slice(ownership, func(i, j int) bool {
+		if ownership[i].Repository != ownership[j].Repository {
+			return ownership[i].Repository < ownership[j].Repository
+		}
+		return ownership[i].Path < ownership[j].Path
+	})
+	return ownership
+}
+
+// ancestors lists the directories of a file path, from the root "." down to
+// at most depth levels (all of them when depth is negative).
+func ancestors(file string, depth int) []string {
+	dirs := []string{"."}
+	parts := strings.Split(path.Dir(file), "/")
+	if parts[0] == "." {
+		return dirs
+	}
+	for i := 1; i <= len(parts) && (depth < 0 || i <= depth); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "/"))
+	}
+	return dirs
+}
+
+// ownershipLevel is how many levels deep a directory is, 0 for the root.
+func ownershipLevel(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// FilterOwnership keeps the directories under the directory prefix, the
+// directory itself included (see underPath), and, when inactiveOnly is set,
+// only those whose top owner is inactive.
+func FilterOwnership(dirs []DirectoryOwnership, prefix string, inactiveOnly bool) []DirectoryOwnership {
+	var kept []DirectoryOwnership
+	for _, d := range dirs {
+		if !underPath(d.Path, prefix) {
+			continue
+		}
+		if inactiveOnly && !d.TopOwnerInactive {
+			continue
+		}
+		kept = append(kept, d)
+	}
+	return kept
+}
+
+// PrintOwnership prints an ownership table, then the directories whose top
+// owner is inactive.
+func PrintOwnership(dirs []DirectoryOwnership) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DIRECTORY\tCHANGES\tAUTHORS\tBUS FACTOR\tTOP OWNER")
+	var orphaned []DirectoryOwnership
+	for _, d := range dirs {
+		top := d.Owners[0]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s (%.0f%%)\n", ownershipLabel(d, dirs), d.Changes, d.Authors, d.BusFactor, top.Author, top.Share*100)
+		if d.TopOwnerInactive {
+			orphaned = append(orphaned, d)
+		}
+	}
+	_ = w.Flush()
+	if len(orphaned) == 0 {
+		return
+	}
+	fmt.Println()
+	Print(Header, "Directories whose top owner is inactive")
+	fmt.Println()
+	for _, d := range orphaned {
+		top := d.Owners[0]
+		fmt.Printf("%s: %s, last commit %s\n", ownershipLabel(d, dirs), top.Author, top.LastCommit.Format(dateLayout))
+	}
+}
+
+// ownershipLabel names a directory, prefixed with its repository when the
+// report covers several.
+func ownershipLabel(d DirectoryOwnership, dirs []DirectoryOwnership) string {
+	if len(dirs) > 0 && dirs[0].Repository != dirs[len(dirs)-1].Repository {
+		return d.Repository + ":" + d.Path
+	}
+	return d.Path
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestAncestors(t *testing.T) {
+	cases := map[string][]string{
+		"main.go":                     {"."},
+		"services/billing/api/pay.go": {".", "services", "services/billing"},
+		"services/billing/invoice.go": {".", "services", "services/billing"},
+		"web/index.html":              {".", "web"},
+	}
+	for file, want := range cases {
+		if got := ancestors(file, 2); !reflect.DeepEqual(got, want) {
+			t.Errorf("ancestors(%q, 2) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestBuildOwnership(t *testing.T) {
+	end := time.Date(2026, time.June, 30, 23, 59, 59, 0, time.UTC)
+	files := map[fileKey]*fileEditions{
+		{Repo: "r", Path: "billing/a.go"}: {Authors: map[string]int{"email:alice@e": 60, "email:bob@e": 30}},
+		{Repo: "r", Path: "billing/b.go"}: {Authors: map[string]int{"email:carol@e": 10}},
+		{Repo: "r", Path: "web/app.js"}:   {Authors: map[string]int{"email:bob@e": 40, "email:carol@e": 40, "email:alice@e": 20}},
+	}
+	names := map[string]string{"email:alice@e": "Alice", "email:bob@e": "Bob"}
+	lastCommits := map[string]time.Time{
+		"email:alice@e": end.AddDate(0, 0, -200), // inactive
+		"email:bob@e":   end.AddDate(0, 0, -1),
+		"email:carol@e": end.AddDate(0, 0, -1),
+	}
+	dirs := buildOwnership(files, names, lastCommits, 1, 90, end)
+	if len(dirs) != 3 || dirs[0].Path != "." || dirs[1].Path != "billing" || dirs[2].Path != "web" {
+		t.Fatalf("directories %+v, want ., billing and web", dirs)
+	}
+
+	billing := dirs[1]
+	if billing.Changes != 100 || billing.Authors != 3 || billing.BusFactor != 1 {
+		t.Errorf("billing = %+v, want 100 changes by 3 authors, bus factor 1", billing)
+	}
+	if top := billing.Owners[0]; top.Author != "Alice" || top.Share != 0.6 || !billing.TopOwnerInactive {
+		t.Errorf("billing top owner %+v, want an inactive Alice with 60%%", top)
+	}
+	if billing.Owners[2].Author != "carol@e" {
+		t.Errorf("an unnamed owner should show their email, got %q", billing.Owners[2].Author)
+	}
+
+	web := dirs[2]
+	if web.BusFactor != 2 || web.TopOwnerInactive {
+		t.Errorf("web = %+v, want bus factor 2 and an active top owner", web)
+	}
+	if got := FilterOwnership(dirs, "billing/", true); len(got) != 1 || got[0].Path != "billing" {
+		t.Errorf("inactive directories under billing/ %+v, want billing", got)
+	}
+	if got := FilterOwnership(dirs, "b", false); len(got) != 0 {
+		t.Errorf("directories under b %+v, want none: b is not a directory", got)
+	}
+}
+
+func TestLaunchOwnership(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	now := time.Now()
+	old := &object.Signature{Name: "alice", Email: "alice@example.com", When: now.AddDate(0, 0, -120)}
+	commitAs(t, path, "billing/a.go", "a\nb\nc\n", "feat: billing", old, old)
+	commitFile(t, path, "web/app.js", "x\n", "feat: web", "bob", now.Add(-time.Hour))
+
+	opts := LaunchOptions{DurationInWeeks: 26, Folders: []string{path}, Dashboard: true}
+	if agg := Aggregate(Launch(opts)); agg.Ownership != nil {
+		t.Error("ownership should only be computed when asked")
+	}
+	opts.OwnershipDepth = 1
+	agg := Aggregate(Launch(opts))
+	// alice, inactive, owns the root through billing; bob owns web.
+	inactive := FilterOwnership(agg.Ownership, "", true)
+	if len(agg.Ownership) != 3 || len(inactive) != 2 || inactive[0].Path != "." || inactive[1].Path != "billing" {
+		t.Errorf("ownership %+v, want the root and billing owned by an inactive alice", agg.Ownership)
+	}
+}
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -14,6 +15,31 @@ const (
 	Dashboard OutputType = 1
 )
 
+// Output formats of the stat command.
+const (
+	FormatText     = "text"     // the colored heatmap
+	FormatMarkdown = "markdown" // a summary, see WriteMarkdown
+	FormatJSON     = "json"     // the aggregated statistics, see WriteJSON
+	FormatNDJSON   = "ndjson"   // the statistics of each result, see WriteNDJSON
+)
+
+// OutputFormats lists the output formats of the stat command.
+var OutputFormats = []string{FormatText, FormatMarkdown, FormatJSON, FormatNDJSON}
+
+// ParseFormat validates an output format of the stat command.
+func ParseFormat(value string) (string, error) {
+	return parseChoice("format", value, OutputFormats)
+}
+
+// parseChoice validates a value that must be one of choices; kind names it in
+// the error.
+func parseChoice(kind, value string, choices []string) (string, error) {
+	if !slices.Contains(choices, value) {
+		return "", fmt.Errorf("invalid %s %q, use %s", kind, value, strings.Join(choices, ", "))
+	}
+	return value, nil
+}
+
 type StatsResultConsolePrinter struct {
 	OutputType OutputType
 }
@@ -70,10 +96,12 @@ func Print(c TermStyle, s string) {
 // printMonths prints the month names in the first line, determining when the month
 // changed between switching weeks
 func getMonths(r *StatsResult, limitWeeks int) string {
-	week := getBeginningOfDay(r.EndOfScan).Add(-(time.Duration(r.DurationInDays) * time.Hour * 24))
+	// The days from the first day of the window to the last one.
+	span := r.DurationInDays - 1
+	week := getBeginningOfDay(r.EndOfScan).Add(-(time.Duration(span) * time.Hour * 24))
 	month := week.Month()
 	out := "    "
-	i := r.DurationInDays
+	i := span
 	for week.Before(r.EndOfScan) {
 		if limitWeeks > 0 && i > limitWeeks*7 {
 			i -= 7
@@ -104,7 +132,7 @@ func getDayCol(day int) string {
 func (p StatsResultConsolePrinter) getCells(keys []int, r *StatsResult, limitWeeks int) string {
 	out := ""
 	out += getMonths(r, limitWeeks)
-	durationInWeeks := r.DurationInDays / 7
+	durationInWeeks := (r.DurationInDays - 1) / 7
 
 	begin := r.BeginOfScan // .AddDate(0, 0, int(-offset))
 	end := getEndOfDay(r.EndOfScan)
@@ -126,8 +154,7 @@ func (p StatsResultConsolePrinter) getCells(keys []int, r *StatsResult, limitWee
 				// first week print weekday
 				out += getDayCol(int(current.Weekday()))
 			}
-			day := end.Sub(current).Hours() / 24
-			out += p.getCell(r.Commits[int(day+8)], current)
+			out += p.getCell(r.Commits[r.dayIndex(current)], current)
 
 			if daysBetween(current, r.EndOfScan) < 7 {
 				// last week return to begin
@@ -165,11 +192,13 @@ func (p StatsResultConsolePrinter) getCell(val int, date time.Time) string {
 	case date.Day() == 1:
 		// first of month
 		return p.colorize(FirstOfMonth, cellContent)
-	case val == 0:
+	}
+	switch cellLevel(val) {
+	case levelEmpty:
 		return p.colorize(Empty, "  - ")
-	case val > 0 && val < 5:
+	case levelLow:
 		return p.colorize(ValueLow, cellContent)
-	case val >= 5 && val < 10:
+	case levelMiddle:
 		return p.colorize(ValueMiddle, cellContent)
 	default:
 		return p.colorize(ValueHigh, cellContent)
@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// CommitRecord is a single commit as a scan counted it, before aggregation:
+// the raw facts of the commit plus how the scan attributed it.
+type CommitRecord struct {
+	Repository     string    `json:"repository"`
+	Hash           string    `json:"hash"`
+	Merge          bool      `json:"merge"`
+	AuthorName     string    `json:"authorName"`
+	AuthorEmail    string    `json:"authorEmail"`
+	AuthorWhen     time.Time `json:"authorWhen"`
+	CommitterName  string    `json:"committerName"`
+	CommitterEmail string    `json:"committerEmail"`
+	CommitterWhen  time.Time `json:"committerWhen"`
+	// When is the moment the commit is placed at, after the attribution and
+	// timezone options.
+	When      time.Time `json:"when"`
+	Subject   string    `json:"subject"`
+	Type      string    `json:"type"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	// Files are the changed files the file patterns keep.
+	Files []FileChange `json:"files"`
+	// Credited are the identities credited with the commit, after the
+	// .mailmap, the attribution, co-authors and user filter.
+	Credited []CreditedIdentity `json:"credited"`
+}
+
+// FileChange is the lines a commit added to and removed from a file.
+type FileChange struct {
+	Path      string `json:"path"`
+	Language  string `json:"language"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// CreditedIdentity is an identity credited with a commit, and the contributor
+// its alias group is displayed as.
+type CreditedIdentity struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Contributor string `json:"contributor"`
+	CoAuthor    bool   `json:"coAuthor"`
+}
+
+// newCommitRecord records a counted commit of the repository at path.
+func newCommitRecord(path string, c *commitFact, when time.Time, credited []identity) CommitRecord {
+	record := CommitRecord{
+		Repository:     path,
+		Hash:           c.Hash,
+		Merge:          c.isMerge(),
+		AuthorName:     c.AuthorName,
+		AuthorEmail:    c.AuthorEmail,
+		AuthorWhen:     c.AuthorWhen,
+		CommitterName:  c.CommitterName,
+		CommitterEmail: c.CommitterEmail,
+		CommitterWhen:  c.CommitterWhen,
+		When:           when,
+		Subject:        c.Subject,
+		Type:           c.Type,
+		Files:          []FileChange{},
+		Credited:       make([]CreditedIdentity, 0, len(credited)),
+	}
+	for _, id := range credited {
+		record.Credited = append(record.Credited, CreditedIdentity{Name: id.Name, Email: id.Email, CoAuthor: id.coAuthor})
+	}
+	return record
+}
+
+// addFile records a changed file of the commit.
+func (c *CommitRecord) addFile(change fileChange) {
+	c.Files = append(c.Files, FileChange{
+		Path:      change.Name,
+		Language:  languageForFile(change.Name),
+		Additions: change.Additions,
+		Deletions: change.Deletions,
+	})
+	c.Additions += change.Additions
+	c.Deletions += change.Deletions
+}
+
+// buildRecords sorts the records of every repository by time (then by
+// repository and hash) and names the contributor of their credited
+// identities, like the contributors ranking groups them.
+func buildRecords(records []CommitRecord, contributors []Contributor) []CommitRecord {
+	names := make(map[string]string, len(contributors))
+	for _, c := range contributors {
+		names[c.key] = c.Author
+	}
+	for i := range records {
+		for j, id := range records[i].Credited {
+			records[i].Credited[j].Contributor = names[aliasGroupKey(strings.TrimSpace(id.Name), strings.TrimSpace(id.Email))]
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.When.Equal(b.When) {
+			return a.When.Before(b.When)
+		}
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		return a.Hash < b.Hash
+	})
+	return records
+}
+
+// CommitFilter selects commit records by the day they are placed at, From and
+// To included (YYYY-MM-DD, empty for no bound), and by punchcard slot: Weekday
+// (Monday-first, like AggregatedStats.Punchcard) and Hour, negative for any.
+type CommitFilter struct {
+	From, To string
+	Weekday  int
+	Hour     int
+}
+
+// FilterCommits returns the records the filter selects, in their order.
+func FilterCommits(commits []CommitRecord, f CommitFilter) []CommitRecord {
+	selected := []CommitRecord{}
+	for _, c := range commits {
+		day := c.When.Format(dateLayout)
+		if (f.From != "" && day < f.From) || (f.To != "" && day > f.To) {
+			continue
+		}
+		if f.Weekday >= 0 && (int(c.When.Weekday())+6)%7 != f.Weekday {
+			continue
+		}
+		if f.Hour >= 0 && c.When.Hour() != f.Hour {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterCommits(t *testing.T) {
+	path := t.TempDir()
+	initRepo(t, path)
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -3)
+	commitFile(t, path, "a.go", "a\n", "feat: a\n\nbody", "alice", day.Add(9*time.Hour))
+	commitFile(t, path, "b.go", "b\n", "fix: b", "bob", day.Add(14*time.Hour))
+	commitFile(t, path, "c.go", "c\n", "docs: c", "alice", day.AddDate(0, 0, 1).Add(9*time.Hour))
+
+	opts := LaunchOptions{Since: now.AddDate(0, 0, -6).Format(dateLayout), Until: now.Format(dateLayout), Folders: []string{path},
+		Dashboard: true, Records: true, Timezone: "local"}
+	commits := Aggregate(Launch(opts)).Commits
+	if len(commits) != 3 || commits[0].Subject != "feat: a" {
+		t.Fatalf("commits %+v, want 3, the first one with its subject", commits)
+	}
+
+	date := day.Format(dateLayout)
+	weekday := (int(day.Weekday()) + 6) % 7
+	for _, tc := range []struct {
+		name   string
+		filter CommitFilter
+		want   []string
+	}{
+		{"day", CommitFilter{From: date, To: date, Weekday: -1, Hour: -1}, []string{"feat: a", "fix: b"}},
+		{"range", CommitFilter{From: date, Weekday: -1, Hour: -1}, []string{"feat: a", "fix: b", "docs: c"}},
+		{"slot", CommitFilter{Weekday: weekday, Hour: 9}, []string{"feat: a"}},
+		{"hour", CommitFilter{Weekday: -1, Hour: 9}, []string{"feat: a", "docs: c"}},
+		{"none", CommitFilter{To: day.AddDate(0, 0, -1).Format(dateLayout), Weekday: -1, Hour: -1}, nil},
+	} {
+		got := FilterCommits(commits, tc.filter)
+		subjects := []string{}
+		for _, c := range got {
+			subjects = append(subjects, c.Subject)
+		}
+		if len(subjects) != len(tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, subjects, tc.want)
+			continue
+		}
+		for i := range subjects {
+			if subjects[i] != tc.want[i] {
+				t.Errorf("%s: got %q, want %q", tc.name, subjects, tc.want)
+				break
+			}
+		}
+	}
+}
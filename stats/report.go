@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// reportPlaceholder is the script of the web UI that a report fills with the
+// statistics.
+const reportPlaceholder = `<script id="report-data" type="application/json">null</script>`
+
+// WriteReport writes the web UI as a single self-contained HTML page showing
+// agg: the statistics are embedded in the page rather than fetched from the
+// API, so it works offline, from a file:// URL.
+func WriteReport(w io.Writer, agg AggregatedStats, generated time.Time) error {
+	page, err := webUI.ReadFile("webui/index.html")
+	if err != nil {
+		return err
+	}
+	// json.Marshal escapes <, > and &, so the statistics cannot close the
+	// script element.
+	data, err := json.Marshal(statsResponse{AggregatedStats: agg, UpdatedAt: generated})
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(page, []byte(reportPlaceholder)) {
+		return errors.New("the web UI has no report placeholder")
+	}
+	page = bytes.Replace(page, []byte(reportPlaceholder),
+		[]byte(`<script id="report-data" type="application/json">`+string(data)+`</script>`), 1)
+	_, err = w.Write(page)
+	return err
+}
@@ -0,0 +1,33 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWriteReport(t *testing.T) {
+	agg := AggregatedStats{User: "all", TotalCommits: 3, Contributors: []Contributor{{Author: "</script><b>", Commits: 3}}}
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, agg, time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	m := regexp.MustCompile(`(?s)<script id="report-data" type="application/json">(.*?)</script>`).FindStringSubmatch(page)
+	if m == nil {
+		t.Fatal("no embedded statistics")
+	}
+	var embedded statsResponse
+	if err := json.Unmarshal([]byte(m[1]), &embedded); err != nil {
+		t.Fatalf("embedded statistics: %v", err)
+	}
+	if embedded.TotalCommits != 3 || embedded.Contributors[0].Author != "</script><b>" || embedded.UpdatedAt.Year() != 2026 {
+		t.Errorf("embedded statistics %+v", embedded)
+	}
+	if regexp.MustCompile(`(src|href)="https?:`).MatchString(page) {
+		t.Error("the report should not load external resources")
+	}
+}
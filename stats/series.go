@@ -0,0 +1,153 @@
+package stats
+
+import "time"
+
+// DefaultSeriesContributors is how many contributors get a weekly series when
+// the web API is asked for them.
+const DefaultSeriesContributors = 10
+
+// seriesInclude is the include value that asks the web API for the series;
+// it is not a file pattern.
+const seriesInclude = "series"
+
+// WeekCount is the activity of a contributor over one week of the window.
+type WeekCount struct {
+	Week      string `json:"week"` // first day, YYYY-MM-DD
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// ContributorSeries is the weekly activity of a contributor, one entry for
+// every week of the window (empty weeks included).
+type ContributorSeries struct {
+	Author     string      `json:"author"`
+	Identities []string    `json:"identities"`
+	Weeks      []WeekCount `json:"weeks"`
+}
+
+// ContributorDays is the daily activity of a contributor: the days of the
+// window with commits.
+type ContributorDays struct {
+	Author     string     `json:"author"`
+	Identities []string   `json:"identities"`
+	Days       []DayCount `json:"days"`
+}
+
+// weekIndex is the week of t in the window: 0 for the 7 days starting at
+// BeginOfScan, and so on, like the weekly charts of the calendar.
+func (r *StatsResult) weekIndex(t time.Time) int {
+	return (r.dayIndex(r.BeginOfScan) - r.dayIndex(t)) / 7
+}
+
+// addSeries counts a commit of author on the day of when.
+func (r *StatsResult) addSeries(author string, when time.Time, additions, deletions int) {
+	if r.Series[author] == nil {
+		r.Series[author] = make(map[int][3]int)
+	}
+	day := r.dayIndex(when)
+	s := r.Series[author][day]
+	s[0]++
+	s[1] += additions
+	s[2] += deletions
+	r.Series[author][day] = s
+}
+
+// mergeSeries adds every daily counter of src into dst.
+func mergeSeries(dst, src map[string]map[int][3]int) {
+	for author, days := range src {
+		if dst[author] == nil {
+			dst[author] = make(map[int][3]int, len(days))
+		}
+		for day, s := range days {
+			d := dst[author][day]
+			d[0] += s[0]
+			d[1] += s[1]
+			d[2] += s[2]
+			dst[author][day] = d
+		}
+	}
+}
+
+// groupSeries sums the daily counters of the identities of each contributor,
+// over the days of the window, by alias group key.
+func groupSeries(r *StatsResult, series map[string]map[int][3]int, contributors []Contributor) map[string]map[int][3]int {
+	first, last := r.dayIndex(r.BeginOfScan), r.dayIndex(r.EndOfScan)
+	grouped := make(map[string]map[int][3]int, len(contributors))
+	for _, c := range contributors {
+		grouped[c.key] = make(map[int][3]int)
+	}
+	for author, counts := range series {
+		g := grouped[aliasGroupKey(splitAuthorKey(author))]
+		if g == nil {
+			continue
+		}
+		for day, s := range counts {
+			if day > first || day < last {
+				continue
+			}
+			d := g[day]
+			d[0] += s[0]
+			d[1] += s[1]
+			d[2] += s[2]
+			g[day] = d
+		}
+	}
+	return grouped
+}
+
+// buildSeries returns the weekly series of the first n contributors, summing
+// the daily counters of their identities by week.
+func buildSeries(r *StatsResult, series map[string]map[int][3]int, contributors []Contributor, n int) []ContributorSeries {
+	if n > len(contributors) {
+		n = len(contributors)
+	}
+	first := r.dayIndex(r.BeginOfScan)
+	weeks := r.weekIndex(r.EndOfScan) + 1
+	grouped := groupSeries(r, series, contributors[:n])
+
+	result := make([]ContributorSeries, 0, n)
+	for _, c := range contributors[:n] {
+		cs := ContributorSeries{Author: c.Author, Identities: c.Identities, Weeks: make([]WeekCount, weeks)}
+		for week := range cs.Weeks {
+			cs.Weeks[week].Week = r.BeginOfScan.AddDate(0, 0, 7*week).Format(dateLayout)
+		}
+		for day, s := range grouped[c.key] {
+			w := &cs.Weeks[(first-day)/7]
+			w.Commits += s[0]
+			w.Additions += s[1]
+			w.Deletions += s[2]
+		}
+		result = append(result, cs)
+	}
+	return result
+}
+
+// buildContributorDays returns the days with commits of every contributor,
+// oldest first, summing the daily counters of their identities.
+func buildContributorDays(r *StatsResult, series map[string]map[int][3]int, contributors []Contributor) []ContributorDays {
+	first, last := r.dayIndex(r.BeginOfScan), r.dayIndex(r.EndOfScan)
+	grouped := groupSeries(r, series, contributors)
+
+	result := make([]ContributorDays, 0, len(contributors))
+	for _, c := range contributors {
+		cd := ContributorDays{Author: c.Author, Identities: c.Identities, Days: []DayCount{}}
+		g := grouped[c.key]
+		for day := first; day >= last; day-- {
+			s, ok := g[day]
+			if !ok {
+				continue
+			}
+			date := r.BeginOfScan.AddDate(0, 0, first-day)
+			cd.Days = append(cd.Days, DayCount{
+				Date:      date.Format(dateLayout),
+				Count:     s[0],
+				Additions: s[1],
+				Deletions: s[2],
+				Weekday:   int(date.Weekday()),
+			})
+		}
+		result = append(result, cd)
+	}
+	return result
+}
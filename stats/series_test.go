@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLaunchSeries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	begin := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", begin.Add(10*time.Hour))
+	commitFile(t, path, "a.go", "a\nb\n", "feat: b", "alice", begin.AddDate(0, 0, 8))
+	commitFile(t, path, "b.go", "b\nb\nb\n", "feat: b", "bob", begin.AddDate(0, 0, 9))
+	commitFile(t, path, "c.go", "c\n", "feat: c", "carol", begin.AddDate(0, 0, 13))
+
+	opts := LaunchOptions{Since: "2026-03-02", Until: "2026-03-15", Folders: []string{path}, Dashboard: true, Series: 2}
+	agg := Aggregate(Launch(opts))
+	want := []ContributorSeries{
+		{Author: "bob", Identities: []string{"bob@example.com"}, Weeks: []WeekCount{
+			{Week: "2026-03-02"},
+			{Week: "2026-03-09", Commits: 1, Additions: 3},
+		}},
+		{Author: "alice", Identities: []string{"alice@example.com"}, Weeks: []WeekCount{
+			{Week: "2026-03-02", Commits: 1, Additions: 1},
+			{Week: "2026-03-09", Commits: 1, Additions: 1},
+		}},
+	}
+	if !reflect.DeepEqual(agg.Series, want) {
+		t.Errorf("series %+v, want %+v", agg.Series, want)
+	}
+
+	opts.Series = 0
+	if agg := Aggregate(Launch(opts)); agg.Series != nil || agg.ContributorDays != nil {
+		t.Errorf("series %+v, want none", agg.Series)
+	}
+
+	agg = Aggregate(Launch(PerContributorEvents(opts)))
+	if len(agg.ContributorDays) != 3 || agg.Series != nil {
+		t.Fatalf("%d contributor days and series %+v, want one per contributor and no series", len(agg.ContributorDays), agg.Series)
+	}
+	wantDays := []DayCount{
+		{Date: "2026-03-02", Count: 1, Additions: 1, Weekday: 1},
+		{Date: "2026-03-10", Count: 1, Additions: 1, Weekday: 2},
+	}
+	if alice := agg.ContributorDays[1]; alice.Author != "alice" || !reflect.DeepEqual(alice.Days, wantDays) {
+		t.Errorf("alice days %+v, want %+v", alice, wantDays)
+	}
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema creates the tables of an SQLite export and their indexes.
+// Times are RFC 3339 strings with their UTC offset; day is the calendar day
+// of the attributed time, like the calendar of the statistics.
+const sqliteSchema = `
+CREATE TABLE repositories (
+	id        INTEGER PRIMARY KEY,
+	path      TEXT NOT NULL UNIQUE,
+	commits   INTEGER NOT NULL,
+	additions INTEGER NOT NULL,
+	deletions INTEGER NOT NULL
+);
+CREATE TABLE contributors (
+	id           INTEGER PRIMARY KEY,
+	name         TEXT NOT NULL,
+	commits      INTEGER NOT NULL,
+	co_authored  INTEGER NOT NULL,
+	additions    INTEGER NOT NULL,
+	deletions    INTEGER NOT NULL,
+	first_commit TEXT,
+	last_commit  TEXT
+);
+CREATE TABLE identities (
+	id             INTEGER PRIMARY KEY,
+	name           TEXT NOT NULL,
+	email          TEXT NOT NULL,
+	contributor_id INTEGER REFERENCES contributors (id),
+	UNIQUE (name, email)
+);
+CREATE INDEX identities_contributor ON identities (contributor_id);
+CREATE TABLE commit_types (
+	type    TEXT PRIMARY KEY,
+	commits INTEGER NOT NULL
+);
+CREATE TABLE commits (
+	id              INTEGER PRIMARY KEY,
+	repository_id   INTEGER NOT NULL REFERENCES repositories (id),
+	hash            TEXT NOT NULL,
+	merge           INTEGER NOT NULL,
+	author_name     TEXT NOT NULL,
+	author_email    TEXT NOT NULL,
+	author_time     TEXT NOT NULL,
+	committer_name  TEXT NOT NULL,
+	committer_email TEXT NOT NULL,
+	committer_time  TEXT NOT NULL,
+	time            TEXT NOT NULL,
+	day             TEXT NOT NULL,
+	subject         TEXT NOT NULL,
+	type            TEXT NOT NULL REFERENCES commit_types (type),
+	additions       INTEGER NOT NULL,
+	deletions       INTEGER NOT NULL,
+	UNIQUE (repository_id, hash)
+);
+CREATE INDEX commits_day ON commits (day);
+CREATE INDEX commits_type ON commits (type);
+CREATE TABLE commit_identities (
+	commit_id   INTEGER NOT NULL REFERENCES commits (id),
+	identity_id INTEGER NOT NULL REFERENCES identities (id),
+	co_author   INTEGER NOT NULL,
+	PRIMARY KEY (commit_id, identity_id)
+);
+CREATE INDEX commit_identities_identity ON commit_identities (identity_id);
+CREATE TABLE file_changes (
+	commit_id INTEGER NOT NULL REFERENCES commits (id),
+	path      TEXT NOT NULL,
+	language  TEXT NOT NULL,
+	additions INTEGER NOT NULL,
+	deletions INTEGER NOT NULL,
+	PRIMARY KEY (commit_id, path)
+);
+CREATE INDEX file_changes_path ON file_changes (path);
+CREATE INDEX file_changes_language ON file_changes (language);
+`
+
+// WriteSQLite writes the commits of the aggregated statistics (see the
+// Records launch option) to a new SQLite database at path, replacing any
+// file there: the repositories, contributors and their identities, commit
+// types, commits, the identities credited with each commit and the changed
+// files.
+func WriteSQLite(path string, agg AggregatedStats) (err error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := db.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	insert := func(query string, args ...any) (int64, error) {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+
+	contributors := make(map[string]int64, len(agg.Contributors)) // alias group key -> id
+	for _, c := range agg.Contributors {
+		id, err := insert(`INSERT INTO contributors (name, commits, co_authored, additions, deletions, first_commit, last_commit) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			c.Author, c.Commits, c.CoAuthored, c.Additions, c.Deletions, sqliteTime(c.FirstCommit), sqliteTime(c.LastCommit))
+		if err != nil {
+			return err
+		}
+		contributors[c.key] = id
+	}
+	for _, t := range agg.CommitTypes {
+		if _, err := insert(`INSERT INTO commit_types (type, commits) VALUES (?, ?)`, t.Type, t.Count); err != nil {
+			return err
+		}
+	}
+
+	repositories := map[string]int64{}
+	identities := map[[2]string]int64{}
+	for _, c := range agg.Commits {
+		repo, ok := repositories[c.Repository]
+		if !ok {
+			if repo, err = insert(`INSERT INTO repositories (path, commits, additions, deletions) VALUES (?, 0, 0, 0)`, c.Repository); err != nil {
+				return err
+			}
+			repositories[c.Repository] = repo
+		}
+		if _, err := tx.Exec(`UPDATE repositories SET commits = commits + 1, additions = additions + ?, deletions = deletions + ? WHERE id = ?`,
+			c.Additions, c.Deletions, repo); err != nil {
+			return err
+		}
+
+		commit, err := insert(`INSERT INTO commits (repository_id, hash, merge, author_name, author_email, author_time, committer_name, committer_email, committer_time, time, day, subject, type, additions, deletions)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			repo, c.Hash, c.Merge, c.AuthorName, c.AuthorEmail, sqliteTime(c.AuthorWhen), c.CommitterName, c.CommitterEmail, sqliteTime(c.CommitterWhen),
+			sqliteTime(c.When), c.When.Format(dateLayout), c.Subject, c.Type, c.Additions, c.Deletions)
+		if err != nil {
+			return err
+		}
+
+		for _, credited := range c.Credited {
+			key := [2]string{credited.Name, credited.Email}
+			id, ok := identities[key]
+			if !ok {
+				var contributor any
+				if cid, ok := contributors[aliasGroupKey(strings.TrimSpace(credited.Name), strings.TrimSpace(credited.Email))]; ok {
+					contributor = cid
+				}
+				if id, err = insert(`INSERT INTO identities (name, email, contributor_id) VALUES (?, ?, ?)`, credited.Name, credited.Email, contributor); err != nil {
+					return err
+				}
+				identities[key] = id
+			}
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO commit_identities (commit_id, identity_id, co_author) VALUES (?, ?, ?)`, commit, id, credited.CoAuthor); err != nil {
+				return err
+			}
+		}
+		for _, f := range c.Files {
+			if _, err := tx.Exec(`INSERT INTO file_changes (commit_id, path, language, additions, deletions) VALUES (?, ?, ?, ?, ?)`,
+				commit, f.Path, f.Language, f.Additions, f.Deletions); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// sqliteTime formats a time for SQLite, NULL for the zero time.
+func sqliteTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
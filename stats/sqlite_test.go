@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "repo")
+	initRepo(t, path)
+	now := time.Now()
+	commitFile(t, path, "a.go", "a\nb\n", "feat: a", "alice", now.AddDate(0, 0, -3))
+	// The same email under another name is the same contributor.
+	alias := &object.Signature{Name: "Alice B.", Email: "alice@example.com", When: now.AddDate(0, 0, -2)}
+	hash := commitAs(t, path, "README.md", "hi\n", "docs: readme\n\nCo-authored-by: Bob <bob@example.com>", alias, alias)
+
+	opts := LaunchOptions{Since: now.AddDate(0, 0, -6).Format(dateLayout), Until: now.Format(dateLayout), Folders: []string{path},
+		Dashboard: true, CoAuthors: true, Records: true}
+	agg := Aggregate(Launch(opts))
+	if len(agg.Commits) != 2 || agg.Commits[1].Hash != hash || agg.Commits[1].Credited[0].Contributor != "alice" {
+		t.Fatalf("commits %+v, want both, the newest credited to alice", agg.Commits)
+	}
+
+	db := filepath.Join(dir, "stats.db")
+	if err := WriteSQLite(db, agg); err != nil {
+		t.Fatal(err)
+	}
+	// Writing again replaces the database.
+	if err := WriteSQLite(db, agg); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := sql.Open("sqlite", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{`SELECT COUNT(*) || '/' || SUM(additions) FROM commits`, "2/3"},
+		{`SELECT commits || '/' || additions FROM repositories WHERE path = '` + path + `'`, "2/3"},
+		{`SELECT GROUP_CONCAT(name, ',') FROM (SELECT c.name FROM contributors c JOIN identities i ON i.contributor_id = c.id
+			JOIN commit_identities ci ON ci.identity_id = i.id JOIN commits m ON m.id = ci.commit_id WHERE m.hash = '` + hash + `' ORDER BY ci.co_author)`, "alice,Bob"},
+		{`SELECT COUNT(*) FROM identities WHERE email = 'alice@example.com'`, "2"},
+		{`SELECT language FROM file_changes f JOIN commits m ON m.id = f.commit_id WHERE m.hash = '` + hash + `'`, "Markdown"},
+		{`SELECT GROUP_CONCAT(type || ':' || commits, ',') FROM (SELECT * FROM commit_types ORDER BY type)`, "docs:1,feat:1"},
+	} {
+		var got string
+		if err := conn.QueryRow(tc.query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
@@ -4,20 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"os"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/schollz/progressbar/v3"
 )
 
-const outOfRange = 99999
-
 var DefaultDurationInDays = 365
 
 type LaunchOptions struct {
@@ -29,6 +27,56 @@ type LaunchOptions struct {
 	Dashboard        bool
 	PatternToExclude []string
 	PatternToInclude []string
+	Jobs             int // repositories scanned concurrently; <= 0 means one per CPU
+	// History keeps the commit facts across launches, so a relaunch only reads
+	// the new commits. Nil reads every repository history in full.
+	History *HistoryStore
+	Backend string // BackendGoGit (default) or BackendGit
+	// The refs whose history is scanned: HEAD, unless Refs names explicit refs
+	// (branches, tags, hashes), plus every local or remote branch when asked.
+	// A commit reachable from several refs is counted once.
+	AllBranches    bool
+	RemoteBranches bool
+	Refs           []string
+	// CoAuthors also credits a commit to the Co-authored-by trailers of its
+	// message, in the contributors ranking and for the user filter.
+	CoAuthors bool
+	// AttributeBy selects whether the author (default) or the committer of a
+	// commit is matched by the user filter, credited in the contributors and
+	// places the commit in the calendar, hours and punchcard.
+	AttributeBy string
+	// Merges is the merge commit policy: MergesInclude (default),
+	// MergesExclude or MergesOnly.
+	Merges string
+	// Since and Until set an exact scan window instead of DurationInWeeks and
+	// Delta: see parseWindow for the accepted values.
+	Since string
+	Until string
+	// Timezone converts commit times before they are bucketed by day, hour
+	// and weekday: TimezoneCommit (default), TimezoneLocal, TimezoneUTC or
+	// an IANA name.
+	Timezone string
+	// TopFiles is how many files AggregatedStats.Files lists, the most
+	// changed first: DefaultTopFiles when 0, every file when negative.
+	TopFiles int
+	// OwnershipDepth is how many directory levels AggregatedStats.Ownership
+	// covers: none when 0, every level when negative.
+	OwnershipDepth int
+	// InactiveDays is how many days without a commit, before the end of the
+	// window, make a contributor inactive: DefaultInactiveDays when 0, every
+	// contributor of the history when negative.
+	InactiveDays int
+	// SurvivingLines also blames the HEAD tree of every repository to tell
+	// who wrote the lines that exist today. It is expensive on a first scan.
+	SurvivingLines bool
+	// Series is how many contributors, the top ones first, get a weekly
+	// series in AggregatedStats.Series; none when 0.
+	Series int
+	// ContributorDays lists the days with commits of every contributor in
+	// AggregatedStats.ContributorDays.
+	ContributorDays bool
+	// Records also keeps every counted commit in AggregatedStats.Commits.
+	Records bool
 }
 
 type StatsResult struct {
@@ -41,10 +89,17 @@ type StatsResult struct {
 	HoursCommits     [24]int
 	DayCommits       [7]int
 	AuthorsEditions  map[string]map[string]int
+	Offsets          map[string]map[string]int // author -> UTC offset (±hh:mm) -> commits
 	LanguageEditions map[string]map[string]int
 	CommitTypes      map[string]int
-	DayEditions      map[int][2]int // day index -> [additions, deletions]
-	Punchcard        [7][24]int     // [weekday (0=Sunday)][hour] -> commit count
+	DayEditions      map[int][2]int            // day index -> [additions, deletions]
+	Punchcard        [7][24]int                // [weekday (0=Sunday)][hour] -> commit count
+	MergeCommits     int                       // how many of the counted commits are merges
+	Files            map[fileKey]*fileEditions // file -> activity
+	Spans            map[string]commitSpan     // author -> first and last commit, full history
+	SurvivingLines   map[string]map[string]int // author -> language -> lines at HEAD
+	Series           map[string]map[int][3]int // author -> day index -> [commits, additions, deletions]
+	Records          []CommitRecord            // counted commits, when asked
 	Error            error
 }
 
@@ -56,6 +111,24 @@ type StatsOptions struct {
 	Silent               bool
 	PatternToExclude     []string
 	PatternToInclude     []string
+	History              *HistoryStore
+	Backend              string
+	AllBranches          bool
+	RemoteBranches       bool
+	Refs                 []string
+	CoAuthors            bool
+	AttributeBy          string
+	Merges               string
+	Since                string
+	Until                string
+	Timezone             string
+	TopFiles             int
+	OwnershipDepth       int
+	InactiveDays         int
+	SurvivingLines       bool
+	Series               int
+	ContributorDays      bool
+	Records              bool
 }
 
 // IsRepo reports whether path is (the root of) a git repository.
@@ -85,40 +158,31 @@ func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
 // TODO use an interface object in order to refacto in same place the statistic run logic and then print results
 
 func Launch(opts LaunchOptions) []*StatsResult {
-	results := []*StatsResult{}
-	var wg sync.WaitGroup
 	bar := newProgressBar(opts.Dashboard)
 
-	// When merging, every folder is scanned into a single result; otherwise
-	// each folder is scanned independently.
-	var folderSets [][]string
-	if opts.Merge {
-		folderSets = [][]string{opts.Folders}
-	} else {
-		for _, folder := range opts.Folders {
-			folderSets = append(folderSets, []string{folder})
-		}
+	// Every folder is scanned into its own partial result by a bounded pool of
+	// workers; when merging, the partials are then folded into a single result.
+	partials := make([]*StatsResult, 0, len(opts.Folders))
+	for _, folder := range opts.Folders {
+		partials = append(partials, newStatsResult(opts, []string{folder}))
 	}
+	scanAll(partials, opts.Jobs, bar)
+	_ = bar.Finish()
 
-	for _, folders := range folderSets {
-		r := &StatsResult{
-			Options: StatsOptions{
-				EmailOrUsername:      opts.User,
-				DurationParamInWeeks: opts.DurationInWeeks,
-				Folders:              folders,
-				Delta:                opts.Delta,
-				Silent:               opts.Dashboard,
-				PatternToExclude:     opts.PatternToExclude,
-				PatternToInclude:     opts.PatternToInclude,
-			},
+	results := partials
+	if opts.Merge {
+		merged := newStatsResult(opts, opts.Folders)
+		if merged.Error == nil {
+			merged.initMaps()
+			for _, p := range partials {
+				merged.merge(p)
+			}
+			if merged.Error == nil {
+				merged.Folder = strings.Join(opts.Folders, ",")
+			}
 		}
-		populateDurationInDays(opts, r)
-		results = append(results, r)
-		wg.Add(1)
-		go Stats(r, &wg, bar)
+		results = []*StatsResult{merged}
 	}
-	wg.Wait()
-	_ = bar.Finish()
 
 	for _, r := range results {
 		if !opts.Dashboard {
@@ -130,6 +194,73 @@ func Launch(opts LaunchOptions) []*StatsResult {
 	return results
 }
 
+// newStatsResult prepares an empty result scanning folders with the launch
+// options, its scan window already resolved.
+func newStatsResult(opts LaunchOptions, folders []string) *StatsResult {
+	r := &StatsResult{
+		Options: StatsOptions{
+			EmailOrUsername:      opts.User,
+			DurationParamInWeeks: opts.DurationInWeeks,
+			Folders:              folders,
+			Delta:                opts.Delta,
+			Silent:               opts.Dashboard,
+			PatternToExclude:     opts.PatternToExclude,
+			PatternToInclude:     opts.PatternToInclude,
+			History:              opts.History,
+			Backend:              opts.Backend,
+			AllBranches:          opts.AllBranches,
+			RemoteBranches:       opts.RemoteBranches,
+			Refs:                 opts.Refs,
+			CoAuthors:            opts.CoAuthors,
+			AttributeBy:          opts.AttributeBy,
+			Merges:               opts.Merges,
+			Since:                opts.Since,
+			Until:                opts.Until,
+			Timezone:             opts.Timezone,
+			TopFiles:             opts.TopFiles,
+			OwnershipDepth:       opts.OwnershipDepth,
+			InactiveDays:         opts.InactiveDays,
+			SurvivingLines:       opts.SurvivingLines,
+			Series:               opts.Series,
+			ContributorDays:      opts.ContributorDays,
+			Records:              opts.Records,
+		},
+	}
+	populateDurationInDays(opts, r)
+	return r
+}
+
+// scanAll scans every result with at most jobs concurrent workers (one per CPU
+// when jobs is not positive). Results whose window could not be resolved are
+// left untouched.
+func scanAll(results []*StatsResult, jobs int, bar *progressbar.ProgressBar) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(results) {
+		jobs = len(results)
+	}
+
+	var wg sync.WaitGroup
+	work := make(chan *StatsResult)
+	for i := 0; i < jobs; i++ {
+		go func() {
+			for r := range work {
+				Stats(r, &wg, bar)
+			}
+		}()
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			continue
+		}
+		wg.Add(1)
+		work <- r
+	}
+	close(work)
+	wg.Wait()
+}
+
 // newProgressBar returns the "Analyzing commits" progress bar. When silent
 // (dashboard and web modes) it writes nowhere, so it does not clutter the
 // terminal on background refreshes.
@@ -178,17 +309,29 @@ func parseDelta(delta string, from time.Time) (time.Time, error) {
 }
 
 func populateDurationInDays(options LaunchOptions, r *StatsResult) {
+	durationInDays := DefaultDurationInDays
+	if options.DurationInWeeks > 0 {
+		durationInDays = options.DurationInWeeks * 7
+	}
+
+	// An explicit window is honored as is, without aligning it on weeks.
+	if options.Since != "" || options.Until != "" {
+		begin, end, err := parseWindow(options.Since, options.Until, durationInDays, time.Now())
+		if err != nil {
+			r.Error = err
+			return
+		}
+		r.BeginOfScan = begin
+		r.EndOfScan = end
+		r.DurationInDays = r.windowDays()
+		return
+	}
+
 	end, err := parseDelta(options.Delta, time.Now())
 	if err != nil {
 		r.Error = err
 		return
 	}
-
-	durationInDays := DefaultDurationInDays
-	if options.DurationInWeeks > 0 {
-		durationInDays = options.DurationInWeeks * 7
-	}
-	r.DurationInDays = durationInDays
 	r.EndOfScan = end
 	r.BeginOfScan = end.AddDate(0, 0, -durationInDays)
 	if int(r.BeginOfScan.Weekday()) != 1 {
@@ -198,9 +341,8 @@ func populateDurationInDays(options LaunchOptions, r *StatsResult) {
 		r.BeginOfScan = getBeginningOfDay(r.BeginOfScan.AddDate(0, 0, offset))
 
 		r.EndOfScan = getEndOfDay(r.EndOfScan.AddDate(0, 0, offset+6))
-		daysBetween := r.EndOfScan.Sub(r.BeginOfScan).Hours() / 24
-		r.DurationInDays = int(daysBetween)
 	}
+	r.DurationInDays = r.windowDays()
 }
 
 func daysBetween(begin time.Time, end time.Time) int {
@@ -234,25 +376,6 @@ func getEndOfDay(t time.Time) time.Time {
 	return startOfDay
 }
 
-// countDaysSinceDate counts how many days passed since the passed `date`
-func countDaysSinceDate(date time.Time, r *StatsResult) int {
-	days := 0
-	endDate := getEndOfDay(r.EndOfScan)
-	if !date.Before(endDate) && !date.Equal(endDate) {
-		return outOfRange
-	} else if date.Equal(endDate) {
-		return days
-	}
-	for date.Before(endDate) {
-		date = date.Add(time.Hour * 24)
-		days++
-		if days > r.DurationInDays {
-			return outOfRange
-		}
-	}
-	return days
-}
-
 // fillCommits given a repository found in `path`, gets the commits and
 // puts them in the `commits` map, returning it when completed
 func fillCommits(r *StatsResult, emailOrUsername *string, path string, bar *progressbar.ProgressBar) error {
@@ -262,14 +385,6 @@ func fillCommits(r *StatsResult, emailOrUsername *string, path string, bar *prog
 		// log.Fatalf("Cannot get stat from folder (not a repository): %s", path)
 		return fmt.Errorf("cannot get stat from folder (not a repository): %s", path)
 	}
-	// Remove one day to end date to be sure parse today date
-	// trueEndDateParse := endDate.AddDate(0, 0, 1)
-	// get the commits history until endDate is not reached
-	iterator, err := repo.Log(&git.LogOptions{Since: &r.BeginOfScan, Until: &r.EndOfScan})
-	if err != nil {
-		log.Fatalf("Cannot get repository history: %s", err)
-		return err
-	}
 	// Compile the include/exclude patterns once, up front, rather than for
 	// every commit stat as we iterate.
 	excludeRegexps, err := compilePatterns(r.Options.PatternToExclude)
@@ -284,145 +399,252 @@ func fillCommits(r *StatsResult, emailOrUsername *string, path string, bar *prog
 	// Resolve author identities through the repository's .mailmap (if any).
 	mailmap := loadMailmap(path)
 
+	// Only the commits that are new since the last scan of this repository
+	// are read; the others come from the history store.
+	history := r.Options.History.repository(path)
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	heads, err := resolveHeads(repo, r.Options)
+	if err != nil {
+		return err
+	}
+	refs, err := repoTips(repo)
+	if err != nil {
+		return err
+	}
+	backend := newBackend(r.Options.Backend, path, repo)
+	commits, err := history.update(heads, refs, backend)
+	if err != nil {
+		return err
+	}
+
+	var users []string
+	if emailOrUsername != nil {
+		users = strings.Split(*emailOrUsername, ",")
+	}
+
+	fillSpans(r, commits, mailmap, users)
+
 	// iterate the commits
-	offset := calcOffset(r.EndOfScan)
-	err = iterator.ForEach(func(c *object.Commit) error {
-		daysAgo := countDaysSinceDate(c.Author.When, r) + offset
-		hour := c.Author.When.Hour()
-		day := int(c.Author.When.Weekday())
-		if daysAgo == outOfRange {
-			return nil
+	location := timezoneLocation(r.Options.Timezone)
+	firstDay, lastDay := r.dayIndex(r.EndOfScan), r.dayIndex(r.BeginOfScan)
+	for _, c := range commits {
+		if c.CommitterWhen.Before(r.BeginOfScan) || c.CommitterWhen.After(r.EndOfScan) {
+			continue
 		}
-
-		authorName, authorEmail := mailmap.Resolve(c.Author.Name, c.Author.Email)
-
-		if emailOrUsername != nil {
-			users := strings.Split(*emailOrUsername, ",")
-			var found bool
-			for _, u := range users {
-				if strings.Contains(u, "@") && authorEmail == u {
-					found = true
-					break
-				} else if authorName == u {
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil
-			}
+		if !keepCommit(c, r.Options.Merges) {
+			continue
+		}
+		when := c.when(r.Options.AttributeBy)
+		_, offset := when.Zone()
+		if location != nil {
+			when = when.In(location)
+		}
+		// The window holds the commits of its calendar days, the same days
+		// the calendar shows them on.
+		daysAgo := r.dayIndex(when)
+		if daysAgo < firstDay || daysAgo > lastDay {
+			continue
+		}
+		hour := when.Hour()
+		day := int(when.Weekday())
+
+		// The commit is credited to its author and, when asked, to each of
+		// its co-authors (or to its committer); with a user filter, only to
+		// the matching ones.
+		credited := filterUsers(creditedIdentities(c, mailmap, r.Options.AttributeBy, r.Options.CoAuthors), users)
+		if len(credited) == 0 {
+			continue
 		}
 
-		// TODO find a solution for improve perf
-		stats, _ := c.Stats()
-		for _, stat := range stats {
-			ignore := false
-			for _, re := range excludeRegexps {
-				if re.MatchString(stat.Name) {
-					ignore = true
-					break
-				}
-			}
-			for _, re := range includeRegexps {
-				if !re.MatchString(stat.Name) {
-					ignore = true
-					continue
-				} else {
-					ignore = false
-					break
-				}
-			}
+		var record *CommitRecord
+		if r.Options.Records {
+			rec := newCommitRecord(path, c, when, credited)
+			record = &rec
+		}
 
-			if ignore {
+		// Both backends diff a merge against its first parent only, so its
+		// line changes are what the merge brought into the branch.
+		additions, deletions := 0, 0
+		for _, stat := range history.diff(backend, c) {
+			if fileIgnored(stat.Name, includeRegexps, excludeRegexps) {
 				continue
 			}
-			authorKey := authorName + authorIDSep + authorEmail
-			if r.AuthorsEditions[authorKey] == nil {
-				r.AuthorsEditions[authorKey] = make(map[string]int, 2)
+			additions += stat.Additions
+			deletions += stat.Deletions
+			if record != nil {
+				record.addFile(stat)
 			}
-			r.AuthorsEditions[authorKey]["additions"] = r.AuthorsEditions[authorKey]["additions"] + stat.Addition
-			r.AuthorsEditions[authorKey]["deletions"] = r.AuthorsEditions[authorKey]["deletions"] + stat.Deletion
 
 			lang := languageForFile(stat.Name)
 			if r.LanguageEditions[lang] == nil {
 				r.LanguageEditions[lang] = make(map[string]int, 2)
 			}
-			r.LanguageEditions[lang]["additions"] = r.LanguageEditions[lang]["additions"] + stat.Addition
-			r.LanguageEditions[lang]["deletions"] = r.LanguageEditions[lang]["deletions"] + stat.Deletion
+			r.LanguageEditions[lang]["additions"] = r.LanguageEditions[lang]["additions"] + stat.Additions
+			r.LanguageEditions[lang]["deletions"] = r.LanguageEditions[lang]["deletions"] + stat.Deletions
+
+			file := r.Files[fileKey{Repo: path, Path: stat.Name}]
+			if file == nil {
+				file = &fileEditions{Authors: make(map[string]int, len(credited))}
+				r.Files[fileKey{Repo: path, Path: stat.Name}] = file
+			}
+			file.add(stat, credited, when)
 
 			de := r.DayEditions[daysAgo]
-			de[0] += stat.Addition
-			de[1] += stat.Deletion
+			de[0] += stat.Additions
+			de[1] += stat.Deletions
 			r.DayEditions[daysAgo] = de
 		}
 
-		if daysAgo <= r.DurationInDays {
-			r.Commits[daysAgo] = r.Commits[daysAgo] + 1
-			r.HoursCommits[hour] = r.HoursCommits[hour] + 1
-			r.DayCommits[day] = r.DayCommits[day] + 1
-			r.CommitTypes[commitType(c.Message)]++
-			r.Punchcard[day][hour]++
+		for _, id := range credited {
+			authorKey := id.Name + authorIDSep + id.Email
+			if r.AuthorsEditions[authorKey] == nil {
+				r.AuthorsEditions[authorKey] = make(map[string]int, 4)
+			}
+			r.AuthorsEditions[authorKey]["additions"] += additions
+			r.AuthorsEditions[authorKey]["deletions"] += deletions
+			r.AuthorsEditions[authorKey]["commits"]++
+			if id.coAuthor {
+				r.AuthorsEditions[authorKey]["coauthored"]++
+			}
+			if r.Offsets[authorKey] == nil {
+				r.Offsets[authorKey] = make(map[string]int, 1)
+			}
+			r.Offsets[authorKey][formatOffset(offset)]++
+			if r.Options.Series > 0 || r.Options.ContributorDays {
+				r.addSeries(authorKey, when, additions, deletions)
+			}
+		}
+
+		if record != nil {
+			r.Records = append(r.Records, *record)
+		}
+		r.Commits[daysAgo] = r.Commits[daysAgo] + 1
+		r.HoursCommits[hour] = r.HoursCommits[hour] + 1
+		r.DayCommits[day] = r.DayCommits[day] + 1
+		r.CommitTypes[c.Type]++
+		r.Punchcard[day][hour]++
+		if c.isMerge() {
+			r.MergeCommits++
 		}
 		_ = bar.Add(1)
-		return nil
-	})
-	if err != nil {
-		log.Fatalf("Error on git-log iterate: %s", err)
-		return err
 	}
 
+	if r.Options.SurvivingLines {
+		return countSurvivingLines(r, repo, history, backend, mailmap, users, includeRegexps, excludeRegexps)
+	}
 	return nil
 }
 
+// fileIgnored reports whether the file patterns leave a file out: when it
+// matches an exclude pattern, or when include patterns are given and it
+// matches none of them. Matching an include pattern overrides the excludes.
+func fileIgnored(name string, includeRegexps, excludeRegexps []*regexp.Regexp) bool {
+	ignore := false
+	for _, re := range excludeRegexps {
+		if re.MatchString(name) {
+			ignore = true
+			break
+		}
+	}
+	for _, re := range includeRegexps {
+		if !re.MatchString(name) {
+			ignore = true
+			continue
+		} else {
+			ignore = false
+			break
+		}
+	}
+	return ignore
+}
+
 // processRepositories given an user email, returns the
 // commits made in the last 6 months
 func processRepositories(r *StatsResult, bar *progressbar.ProgressBar) error {
-	daysInMap := r.DurationInDays
+	r.initMaps()
+	var errReturn error
+	for _, path := range r.Options.Folders {
+		err := fillCommits(r, r.Options.EmailOrUsername, path, bar)
+		if err != nil {
+			// continue for other folders; stderr keeps the documents written
+			// to stdout (JSON, NDJSON, CSV...) parseable.
+			fmt.Fprint(os.Stderr, colorize(Error, fmt.Sprintf("\nError scanning folder repository %s: %s\n", path, err), Console))
+			errReturn = err
+			continue
+		}
+	}
+	return errReturn
+}
+
+// initMaps allocates the result counters, with an empty commit count for
+// every day of the scan window.
+func (r *StatsResult) initMaps() {
+	first, last := r.dayIndex(r.EndOfScan), r.dayIndex(r.BeginOfScan)
 
-	r.Commits = make(map[int]int, daysInMap)
+	r.Commits = make(map[int]int, last-first+1)
 	r.AuthorsEditions = make(map[string]map[string]int)
+	r.Offsets = make(map[string]map[string]int)
 	r.LanguageEditions = make(map[string]map[string]int)
 	r.CommitTypes = make(map[string]int)
 	r.DayEditions = make(map[int][2]int)
-	var errReturn error
-	for i := daysInMap; i > 0; i-- {
+	r.Files = make(map[fileKey]*fileEditions)
+	r.Spans = make(map[string]commitSpan)
+	r.SurvivingLines = make(map[string]map[string]int)
+	r.Series = make(map[string]map[int][3]int)
+	for i := first; i <= last; i++ {
 		r.Commits[i] = 0
 	}
+}
 
-	for _, path := range r.Options.Folders {
-		err := fillCommits(r, r.Options.EmailOrUsername, path, bar)
-		if err != nil {
-			// continue for other folders
-			Print(Error, fmt.Sprintf("\nError scanning folder repository %s: %s\n", path, err))
-			errReturn = err
-			continue
+// merge adds the counters of o, scanned over the same window, into r. An error
+// on o is carried over to r.
+func (r *StatsResult) merge(o *StatsResult) {
+	if o.Error != nil {
+		r.Error = o.Error
+	}
+	for i, n := range o.Commits {
+		r.Commits[i] += n
+	}
+	for i, de := range o.DayEditions {
+		m := r.DayEditions[i]
+		m[0] += de[0]
+		m[1] += de[1]
+		r.DayEditions[i] = m
+	}
+	for h, n := range o.HoursCommits {
+		r.HoursCommits[h] += n
+	}
+	for d, n := range o.DayCommits {
+		r.DayCommits[d] += n
+	}
+	for d := range o.Punchcard {
+		for h, n := range o.Punchcard[d] {
+			r.Punchcard[d][h] += n
 		}
 	}
-	return errReturn
+	mergeEditions(r.AuthorsEditions, o.AuthorsEditions)
+	mergeEditions(r.Offsets, o.Offsets)
+	mergeEditions(r.LanguageEditions, o.LanguageEditions)
+	for t, n := range o.CommitTypes {
+		r.CommitTypes[t] += n
+	}
+	r.MergeCommits += o.MergeCommits
+	mergeFileEditions(r.Files, o.Files)
+	mergeSpans(r.Spans, o.Spans)
+	mergeEditions(r.SurvivingLines, o.SurvivingLines)
+	mergeSeries(r.Series, o.Series)
+	r.Records = append(r.Records, o.Records...)
 }
 
-// calcOffset determines and returns the amount of days missing to fill
-// the last row of the stats graph
-func calcOffset(endDate time.Time) int {
-	var offset int
-	weekday := endDate.Weekday()
-
-	switch weekday {
-	case time.Sunday:
-		offset = 7
-	case time.Monday:
-		offset = 6
-	case time.Tuesday:
-		offset = 5
-	case time.Wednesday:
-		offset = 4
-	case time.Thursday:
-		offset = 3
-	case time.Friday:
-		offset = 2
-	case time.Saturday:
-		offset = 1
-	}
-
-	return offset
+// mergeEditions adds every counter of src into dst.
+func mergeEditions(dst, src map[string]map[string]int) {
+	for key, counters := range src {
+		if dst[key] == nil {
+			dst[key] = make(map[string]int, len(counters))
+		}
+		for name, n := range counters {
+			dst[key][name] += n
+		}
+	}
 }
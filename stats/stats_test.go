@@ -110,3 +110,31 @@ func TestStatWithDelta(tt *testing.T) {
 		t.Cmp(r[0].Error.Error(), "invalid delta value use the format: <int>[y/m/w/d]")
 	}
 }
+
+func TestLaunchMergeSumsPartials(tt *testing.T) {
+	t := td.NewT(tt)
+
+	options := stats.LaunchOptions{
+		DurationInWeeks: 52,
+		Folders:         []string{"..", ".."},
+		Dashboard:       true,
+		Jobs:            2,
+	}
+	unmerged := stats.Launch(options)
+	t.Cmp(unmerged, td.Len(2))
+
+	options.Merge = true
+	merged := stats.Launch(options)
+	t.Cmp(merged, td.Len(1))
+	t.CmpNoError(merged[0].Error)
+
+	total := func(r *stats.StatsResult) int {
+		n := 0
+		for _, c := range r.Commits {
+			n += c
+		}
+		return n
+	}
+	t.Cmp(total(merged[0]), total(unmerged[0])+total(unmerged[1]))
+	t.Cmp(merged[0].Folder, "..,..")
+}
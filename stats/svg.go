@@ -0,0 +1,229 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Charts rendered as SVG.
+const (
+	ChartHeatmap   = "heatmap"
+	ChartPunchcard = "punchcard"
+	ChartLanguages = "languages"
+)
+
+// SVGCharts lists the charts rendered as SVG.
+var SVGCharts = []string{ChartHeatmap, ChartPunchcard, ChartLanguages}
+
+// Activity levels of a day, shared by the terminal heatmap and the SVG charts.
+const (
+	levelEmpty  = iota // no commit
+	levelLow           // 1 to 4 commits
+	levelMiddle        // 5 to 9 commits
+	levelHigh          // 10 commits or more
+)
+
+// cellLevel is the activity level of a commit count.
+func cellLevel(val int) int {
+	switch {
+	case val <= 0:
+		return levelEmpty
+	case val < 5:
+		return levelLow
+	case val < 10:
+		return levelMiddle
+	default:
+		return levelHigh
+	}
+}
+
+// SVG colors of the activity levels, after the terminal styles: ValueLow is
+// uncolored, ValueMiddle green and ValueHigh yellow.
+var svgLevelColors = [...]string{
+	levelEmpty:  "#ebedf0",
+	levelLow:    "#9be9a8",
+	levelMiddle: "#3fb950",
+	levelHigh:   "#d29922",
+}
+
+const (
+	svgTodayColor        = "#bc8cff" // like the Today style
+	svgFirstOfMonthColor = "#39c5cf" // like the FirstOfMonth style
+	svgTextColor         = "#57606a"
+	svgFont              = `font-family="-apple-system,Segoe UI,Helvetica,Arial,sans-serif" font-size="10"`
+)
+
+// svgLanguageColors colors the languages bar, like the web UI; the languages
+// past them are grouped as "Other".
+var svgLanguageColors = []string{"#f85149", "#3fb950", "#d29922", "#2f81f7", "#bc8cff", "#39c5cf"}
+
+const svgOtherColor = "#8b949e"
+
+// ParseChart validates the name of an SVG chart.
+func ParseChart(value string) (string, error) {
+	return parseChoice("chart", value, SVGCharts)
+}
+
+// WriteSVG renders a chart of the aggregated statistics as a standalone SVG
+// image.
+func WriteSVG(w io.Writer, agg AggregatedStats, chart string) error {
+	var svg string
+	switch chart {
+	case ChartHeatmap:
+		svg = heatmapSVG(agg, time.Now())
+	case ChartPunchcard:
+		svg = punchcardSVG(agg)
+	case ChartLanguages:
+		svg = languagesSVG(agg)
+	default:
+		_, err := ParseChart(chart)
+		return err
+	}
+	_, err := io.WriteString(w, svg)
+	return err
+}
+
+// heatmapSVG draws the calendar like the terminal heatmap: a column per week
+// of the window, a row per day from its first day, the month names above, and
+// today and the first day of each month outlined. Today is the day of now in
+// the time zone of the calendar.
+func heatmapSVG(agg AggregatedStats, now time.Time) string {
+	const cell, gap, left, top = 11, 3, 28, 16
+	weeks := (len(agg.Calendar) + 6) / 7
+	width := left + weeks*(cell+gap)
+	height := top + 7*(cell+gap)
+	today := now.In(agg.EndOfScan.Location()).Format(dateLayout)
+
+	var b strings.Builder
+	svgOpen(&b, width, height)
+	for row := 0; row < 7 && row < len(agg.Calendar); row++ {
+		day := time.Weekday(agg.Calendar[row].Weekday).String()[:2]
+		fmt.Fprintf(&b, `<text x="0" y="%d" fill="%s">%s</text>`+"\n", top+row*(cell+gap)+cell-2, svgTextColor, day)
+	}
+	month := ""
+	for i, d := range agg.Calendar {
+		col, row := i/7, i%7
+		x, y := left+col*(cell+gap), top+row*(cell+gap)
+		if row == 0 && d.Date[:7] != month {
+			if month != "" || d.Date[8:] == "01" {
+				date, _ := time.Parse(dateLayout, d.Date)
+				fmt.Fprintf(&b, `<text x="%d" y="10" fill="%s">%s</text>`+"\n", x, svgTextColor, date.Month().String()[:3])
+			}
+			month = d.Date[:7]
+		}
+		stroke := ""
+		switch {
+		case d.Date == today:
+			stroke = fmt.Sprintf(` stroke="%s" stroke-width="2"`, svgTodayColor)
+		case d.Date[8:] == "01":
+			stroke = fmt.Sprintf(` stroke="%s" stroke-width="2"`, svgFirstOfMonthColor)
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"%s><title>%s: %s</title></rect>`+"\n",
+			x, y, cell, cell, svgLevelColors[cellLevel(d.Count)], stroke, d.Date, plural(d.Count, "commit"))
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// punchcardSVG draws the commits per weekday (Monday first) and hour as
+// circles sized by their share of the busiest slot and colored by level.
+func punchcardSVG(agg AggregatedStats) string {
+	const cell, left, top = 22, 32, 16
+	width := left + 24*cell
+	height := top + 7*cell
+	busiest := 0
+	for _, hours := range agg.Punchcard {
+		for _, n := range hours {
+			busiest = max(busiest, n)
+		}
+	}
+
+	var b strings.Builder
+	svgOpen(&b, width, height)
+	for h := 0; h < 24; h += 3 {
+		fmt.Fprintf(&b, `<text x="%d" y="10" fill="%s" text-anchor="middle">%02d</text>`+"\n", left+h*cell+cell/2, svgTextColor, h)
+	}
+	for d, hours := range agg.Punchcard {
+		y := top + d*cell + cell/2
+		day := time.Weekday((d + 1) % 7).String()[:3]
+		fmt.Fprintf(&b, `<text x="0" y="%d" fill="%s">%s</text>`+"\n", y+4, svgTextColor, day)
+		for h, n := range hours {
+			if n == 0 {
+				continue
+			}
+			r := math.Sqrt(float64(n)/float64(busiest)) * (cell/2 - 1)
+			fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="%.1f" fill="%s"><title>%s %02d:00: %s</title></circle>`+"\n",
+				left+h*cell+cell/2, y, math.Max(r, 1.5), svgLevelColors[cellLevel(n)], day, h, plural(n, "commit"))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// languagesSVG draws the share of the lines changed per language as a single
+// bar, with its legend below.
+func languagesSVG(agg AggregatedStats) string {
+	const width, bar, top = 400, 8, 0
+	total := 0
+	for _, l := range agg.Languages {
+		total += l.Total
+	}
+	type slice struct {
+		name  string
+		lines int
+		color string
+	}
+	var slices []slice
+	for i, l := range agg.Languages {
+		if l.Total == 0 {
+			continue
+		}
+		if i < len(svgLanguageColors) {
+			slices = append(slices, slice{l.Name, l.Total, svgLanguageColors[i]})
+			continue
+		}
+		if last := len(slices) - 1; slices[last].color == svgOtherColor {
+			slices[last].lines += l.Total
+		} else {
+			slices = append(slices, slice{"Other", l.Total, svgOtherColor})
+		}
+	}
+
+	const rowHeight, columns = 18, 3
+	height := top + bar + 8 + (len(slices)+columns-1)/columns*rowHeight
+	var b strings.Builder
+	svgOpen(&b, width, height)
+	x := 0.0
+	for _, s := range slices {
+		w := float64(s.lines) / float64(total) * width
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s"><title>%s: %.1f%%</title></rect>`+"\n",
+			x, top, w, bar, s.color, html.EscapeString(s.name), float64(s.lines)/float64(total)*100)
+		x += w
+	}
+	for i, s := range slices {
+		lx := i % columns * (width / columns)
+		ly := top + bar + 8 + i/columns*rowHeight
+		fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="4" fill="%s"/>`+"\n", lx+4, ly+6, s.color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s">%s %.1f%%</text>`+"\n",
+			lx+12, ly+10, svgTextColor, html.EscapeString(s.name), float64(s.lines)/float64(total)*100)
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func svgOpen(b *strings.Builder, width, height int) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" %s>`+"\n",
+		width, height, width, height, svgFont)
+}
+
+// plural reads like "1 commit" or "3 commits".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCellLevel(t *testing.T) {
+	for val, want := range map[int]int{0: levelEmpty, 1: levelLow, 4: levelLow, 5: levelMiddle, 9: levelMiddle, 10: levelHigh, 120: levelHigh} {
+		if got := cellLevel(val); got != want {
+			t.Errorf("cellLevel(%d) = %d, want %d", val, got, want)
+		}
+	}
+}
+
+func TestHeatmapSVG(t *testing.T) {
+	tokyo := time.FixedZone("", 9*3600)
+	agg := AggregatedStats{EndOfScan: time.Date(2026, 3, 5, 23, 59, 59, 0, tokyo), Calendar: []DayCount{
+		{Date: "2026-03-01", Count: 1, Weekday: 0},
+		{Date: "2026-03-02", Count: 0, Weekday: 1},
+		{Date: "2026-03-03", Count: 3, Weekday: 2},
+		{Date: "2026-03-04", Count: 7, Weekday: 3},
+		{Date: "2026-03-05", Count: 12, Weekday: 4},
+	}}
+	// Still March 3rd in UTC, but already the 4th in the zone of the calendar.
+	svg := heatmapSVG(agg, time.Date(2026, 3, 3, 20, 0, 0, 0, time.UTC))
+	for _, want := range []string{
+		`fill="#9be9a8" stroke="#39c5cf" stroke-width="2"><title>2026-03-01: 1 commit</title>`,
+		`fill="#ebedf0"><title>2026-03-02: 0 commits</title>`,
+		`fill="#9be9a8"><title>2026-03-03: 3 commits</title>`,
+		`fill="#3fb950" stroke="#bc8cff" stroke-width="2"><title>2026-03-04: 7 commits</title>`,
+		`fill="#d29922"><title>2026-03-05: 12 commits</title>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("heatmap misses %s:\n%s", want, svg)
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	agg := AggregatedStats{
+		Calendar:  []DayCount{{Date: "2026-03-02", Count: 1, Weekday: 1}},
+		Languages: []Language{{Name: "C++ & <C>", Total: 3}, {Name: "Go", Total: 1}},
+	}
+	agg.Punchcard[0][9] = 2
+	for _, chart := range SVGCharts {
+		var buf bytes.Buffer
+		if err := WriteSVG(&buf, agg, chart); err != nil {
+			t.Fatal(err)
+		}
+		d := xml.NewDecoder(&buf)
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: invalid SVG: %v", chart, err)
+			}
+		}
+	}
+	if err := WriteSVG(io.Discard, agg, "pie"); err == nil {
+		t.Error("an unknown chart should be rejected")
+	}
+}
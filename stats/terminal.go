@@ -20,6 +20,8 @@ var (
 	Message      = TermStyle{[]color.Attribute{color.FgGreen, color.BgBlack}}
 	Error        = TermStyle{[]color.Attribute{color.FgRed}}
 	Header       = TermStyle{[]color.Attribute{color.FgMagenta}}
+	Increase     = TermStyle{[]color.Attribute{color.FgGreen}}
+	Decrease     = TermStyle{[]color.Attribute{color.FgRed}}
 )
 
 func colorize(c TermStyle, s string, oType OutputType) string {
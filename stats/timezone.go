@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Time zones commit times can be converted to before bucketing them by day,
+// hour and weekday; any IANA name (e.g. Europe/Paris) is accepted as well.
+const (
+	TimezoneCommit = "commit" // each commit's own recorded offset (the default)
+	TimezoneLocal  = "local"  // the machine running the scan
+	TimezoneUTC    = "utc"
+)
+
+// OffsetCount is how many commits a contributor made from a UTC offset.
+type OffsetCount struct {
+	Offset  string `json:"offset"` // e.g. +02:00
+	Commits int    `json:"commits"`
+}
+
+// ParseTimezone validates a time zone option; an empty one selects the
+// default.
+func ParseTimezone(name string) (string, error) {
+	switch name {
+	case "":
+		return TimezoneCommit, nil
+	case TimezoneCommit, TimezoneLocal, TimezoneUTC:
+		return name, nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", fmt.Errorf("unknown timezone %q, use %s, %s, %s or an IANA name: %w", name, TimezoneCommit, TimezoneLocal, TimezoneUTC, err)
+	}
+	return name, nil
+}
+
+// timezoneLocation is the location commit times are converted to, or nil to
+// keep their own offset.
+func timezoneLocation(name string) *time.Location {
+	switch name {
+	case "", TimezoneCommit:
+		return nil
+	case TimezoneLocal:
+		return time.Local
+	case TimezoneUTC:
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// formatOffset formats a UTC offset in seconds as ±hh:mm.
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign, seconds = '-', -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// offsetCounts lists the commits per UTC offset, the most used first.
+func offsetCounts(offsets map[string]int) []OffsetCount {
+	counts := make([]OffsetCount, 0, len(offsets))
+	for offset, n := range offsets {
+		counts = append(counts, OffsetCount{Offset: offset, Commits: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Commits != counts[j].Commits {
+			return counts[i].Commits > counts[j].Commits
+		}
+		return counts[i].Offset < counts[j].Offset
+	})
+	return counts
+}
@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseTimezone(t *testing.T) {
+	for name, want := range map[string]string{"": TimezoneCommit, "commit": TimezoneCommit, "local": TimezoneLocal, "utc": TimezoneUTC, "Asia/Tokyo": "Asia/Tokyo"} {
+		got, err := ParseTimezone(name)
+		if err != nil || got != want {
+			t.Errorf("ParseTimezone(%q) = (%q, %v), want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseTimezone("Mars/Olympus"); err == nil {
+		t.Error("an unknown time zone should be an error")
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	for seconds, want := range map[int]string{0: "+00:00", 9 * 3600: "+09:00", -(5*3600 + 30*60): "-05:30"} {
+		if got := formatOffset(seconds); got != want {
+			t.Errorf("formatOffset(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestLaunchTimezone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	day := time.Now().AddDate(0, 0, -3)
+	tokyo := time.FixedZone("", 9*3600)
+	newYork := time.FixedZone("", -5*3600)
+	late := time.Date(day.Year(), day.Month(), day.Day(), 23, 30, 0, 0, tokyo) // 14:30 UTC
+	sig := &object.Signature{Name: "alice", Email: "alice@example.com", When: late}
+	commitAs(t, path, "a.go", "a\n", "feat: a", sig, sig)
+	commitFile(t, path, "b.go", "b\n", "feat: b", "alice", late.In(newYork)) // the same instant
+
+	launch := func(timezone string) AggregatedStats {
+		return Aggregate(Launch(LaunchOptions{DurationInWeeks: 4, Folders: []string{path}, Dashboard: true, Timezone: timezone}))
+	}
+	if agg := launch(TimezoneCommit); agg.CommitsByHour[23] != 1 || agg.CommitsByHour[9] != 1 {
+		t.Errorf("commit time zone: commits by hour %v, want one at 23h and one at 9h", agg.CommitsByHour)
+	}
+	agg := launch(TimezoneUTC)
+	if agg.CommitsByHour[14] != 2 {
+		t.Errorf("UTC: commits by hour %v, want both at 14h", agg.CommitsByHour)
+	}
+	want := []OffsetCount{{Offset: "+09:00", Commits: 1}, {Offset: "-05:00", Commits: 1}}
+	if len(agg.Contributors) != 1 || !reflect.DeepEqual(agg.Contributors[0].UTCOffsets, want) {
+		t.Errorf("contributors %+v, want alice with offsets %v", agg.Contributors, want)
+	}
+}
@@ -1,11 +1,14 @@
 package stats
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -19,12 +22,27 @@ var webUI embed.FS
 type appliedParams struct {
 	Weeks    int      `json:"weeks"`
 	Delta    string   `json:"delta"`
+	Since    string   `json:"since"`
+	Until    string   `json:"until"`
 	User     string   `json:"user"`
 	CountAll bool     `json:"countAll"`
 	Merge    bool     `json:"merge"`
 	Repo     string   `json:"repo"`
 	Include  []string `json:"include"`
 	Exclude  []string `json:"exclude"`
+
+	AllBranches    bool     `json:"allBranches"`
+	RemoteBranches bool     `json:"remoteBranches"`
+	Refs           []string `json:"refs"`
+	CoAuthors      bool     `json:"coAuthors"`
+	AttributeBy    string   `json:"attributeBy"`
+	Merges         string   `json:"merges"`
+	Timezone       string   `json:"timezone"`
+	TopFiles       int      `json:"topFiles"`
+	InactiveDays   int      `json:"inactiveDays"`
+	SurvivingLines bool     `json:"survivingLines"`
+	Series         bool     `json:"series"`
+	Compare        string   `json:"compare"`
 }
 
 // statsResponse is the /api/stats payload: the aggregated statistics (flattened
@@ -37,6 +55,24 @@ type statsResponse struct {
 	Stale          bool          `json:"stale"`
 	Refreshing     bool          `json:"refreshing"`
 	TTLSeconds     float64       `json:"ttlSeconds"`
+	// Comparison is set when the compare parameter asks for one.
+	Comparison *Comparison `json:"comparison,omitempty"`
+}
+
+// DefaultCommitsLimit is how many commits a page of /api/commits lists when
+// the request does not say.
+const DefaultCommitsLimit = 100
+
+// MaxCommitsLimit is the most commits a page of /api/commits lists.
+const MaxCommitsLimit = 1000
+
+// commitsResponse is the /api/commits payload: a page of the matching commits
+// and how many match in all.
+type commitsResponse struct {
+	Total   int            `json:"total"`
+	Offset  int            `json:"offset"`
+	Limit   int            `json:"limit"`
+	Commits []CommitRecord `json:"commits"`
 }
 
 // Serve starts an HTTP server exposing the statistics as a JSON API on
@@ -44,9 +80,14 @@ type statsResponse struct {
 // to a JSON file: the default set is scanned at startup, each parameter set is
 // scanned on first use and then served from cache, and a set is refreshed in
 // the background once older than ttl or when /api/refresh is called.
-func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string) error {
+// /metrics exposes the default set and the named metricSets, each an
+// /api/stats query string, to Prometheus.
+func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string, metricSets map[string]string) error {
 	// Keep scans silent: the JSON API is the only response the client sees.
 	opts.Dashboard = true
+	// Keep the commit facts between refreshes, so a refresh only reads the
+	// commits that are new since the previous one.
+	opts.History = NewHistoryStore()
 
 	assets, err := fs.Sub(webUI, "webui")
 	if err != nil {
@@ -69,6 +110,26 @@ func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string)
 		fmt.Printf("Loaded cached statistics from %s\n", cacheFile)
 	}
 
+	// Resolve the metric sets once, and warm them in the background.
+	namedOpts := make(map[string]LaunchOptions, len(metricSets))
+	for name, query := range metricSets {
+		if name == DefaultMetricSet {
+			return fmt.Errorf("metric set %q: the name is reserved", name)
+		}
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			return fmt.Errorf("metric set %q: %w", name, err)
+		}
+		setOpts, err := cache.resolveQuery(q)
+		if err != nil {
+			return fmt.Errorf("metric set %q: %w", name, err)
+		}
+		namedOpts[name] = setOpts
+		if _, stale, _ := cache.state(cacheKey(setOpts)); stale {
+			cache.refreshInBackground(cacheKey(setOpts), setOpts)
+		}
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(assets)))
 
@@ -78,24 +139,18 @@ func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string)
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		key := cacheKey(reqOpts)
-
-		entry, stale, refreshing := cache.state(key)
-		switch {
-		case entry == nil:
-			// First request for this parameter set: scan synchronously.
-			cache.scan(key, reqOpts)
-			entry, stale, refreshing = cache.state(key)
-		case stale:
-			// Stale-while-revalidate: serve now, refresh in the background.
-			refreshing = cache.refreshInBackground(key, reqOpts) || refreshing
+		mode, err := ParseCompare(r.URL.Query().Get("compare"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		entry, stale, refreshing := cache.entryFor(reqOpts)
 		if entry == nil {
 			http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
 			return
 		}
 
-		writeJSON(w, statsResponse{
+		resp := statsResponse{
 			AggregatedStats: entry.Stats,
 			Params:          cache.paramsOf(reqOpts),
 			AvailableRepos:  cache.baseOpts.Folders,
@@ -103,9 +158,194 @@ func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string)
 			Stale:           stale,
 			Refreshing:      refreshing,
 			TTLSeconds:      ttl.Seconds(),
+		}
+		if mode != CompareNone {
+			// The compared window is cached like any other parameter set.
+			prevOpts, err := ComparedOptions(reqOpts, mode)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			prev, _, _ := cache.entryFor(prevOpts)
+			if prev == nil {
+				http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
+				return
+			}
+			comparison := Compare(mode, entry.Stats, prev.Stats)
+			resp.Comparison = &comparison
+			resp.Params.Compare = mode
+		}
+		writeJSON(w, resp)
+	})
+
+	mux.HandleFunc("/api/files", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q := r.URL.Query()
+		by, err := ParseFileSort(q.Get("sort"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit := -1
+		if q.Get("limit") != "" {
+			if limit, err = strconv.Atoi(q.Get("limit")); err != nil || limit < 0 {
+				http.Error(w, "invalid limit: "+q.Get("limit"), http.StatusBadRequest)
+				return
+			}
+		}
+		// Every file is kept, sorted and filtered here.
+		reqOpts.TopFiles = -1
+		entry, _, _ := cache.entryFor(reqOpts)
+		if entry == nil {
+			http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
+			return
+		}
+		files := FilterFiles(append([]FileStat(nil), entry.Stats.Files...), q.Get("prefix"))
+		SortFiles(files, by)
+		writeJSON(w, topFiles(files, limit))
+	})
+
+	mux.HandleFunc("/api/commits", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q := r.URL.Query()
+		filter, err := parseCommitFilter(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset, limit := 0, DefaultCommitsLimit
+		if q.Get("offset") != "" {
+			if offset, err = strconv.Atoi(q.Get("offset")); err != nil || offset < 0 {
+				http.Error(w, "invalid offset: "+q.Get("offset"), http.StatusBadRequest)
+				return
+			}
+		}
+		if q.Get("limit") != "" {
+			if limit, err = strconv.Atoi(q.Get("limit")); err != nil || limit < 0 {
+				http.Error(w, "invalid limit: "+q.Get("limit"), http.StatusBadRequest)
+				return
+			}
+			limit = min(limit, MaxCommitsLimit)
+		}
+		// The commits are kept in memory along with the statistics of the set.
+		all, ok := cache.commitsFor(reqOpts)
+		if !ok {
+			http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
+			return
+		}
+		commits := FilterCommits(all, filter)
+		resp := commitsResponse{Total: len(commits), Offset: offset, Limit: limit}
+		start := min(offset, len(commits))
+		resp.Commits = commits[start : start+min(limit, len(commits)-start)]
+		writeJSON(w, resp)
+	})
+
+	mux.HandleFunc("/api/ownership", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q := r.URL.Query()
+		reqOpts.OwnershipDepth = DefaultOwnershipDepth
+		if q.Get("depth") != "" {
+			depth, err := strconv.Atoi(q.Get("depth"))
+			if err != nil || depth < 1 {
+				http.Error(w, "invalid depth: "+q.Get("depth"), http.StatusBadRequest)
+				return
+			}
+			reqOpts.OwnershipDepth = depth
+		}
+		entry, _, _ := cache.entryFor(reqOpts)
+		if entry == nil {
+			http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, FilterOwnership(entry.Stats.Ownership, q.Get("prefix"), isTrue(q.Get("inactive"))))
+	})
+
+	mux.HandleFunc("/api/export.csv", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		table, err := ParseExportTable(r.URL.Query().Get("table"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="gitcontrib-%s.csv"`, table))
+		cache.serveEntry(w, reqOpts, "text/csv; charset=utf-8", func(out io.Writer, entry *cacheEntry) error {
+			return WriteCSV(out, entry.Stats, table)
+		})
+	})
+
+	mux.HandleFunc("/api/calendar.ics", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		perContributor := isTrue(r.URL.Query().Get("perContributor"))
+		if perContributor {
+			reqOpts = PerContributorEvents(reqOpts)
+		}
+		cache.serveEntry(w, reqOpts, "text/calendar; charset=utf-8", func(out io.Writer, entry *cacheEntry) error {
+			return WriteICS(out, entry.Stats, perContributor, entry.UpdatedAt)
+		})
+	})
+
+	mux.HandleFunc("/api/svg/", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		chart, err := ParseChart(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/svg/"), ".svg"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		cache.serveEntry(w, reqOpts, "image/svg+xml", func(out io.Writer, entry *cacheEntry) error {
+			return WriteSVG(out, entry.Stats, chart)
+		})
+	})
+
+	mux.HandleFunc("/api/badge/", func(w http.ResponseWriter, r *http.Request) {
+		reqOpts, err := cache.resolve(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		metric, err := ParseBadge(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/badge/"), ".svg"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		// Badges are embedded in pages that would otherwise cache them for long.
+		w.Header().Set("Cache-Control", "no-cache")
+		cache.serveEntry(w, reqOpts, "image/svg+xml", func(out io.Writer, entry *cacheEntry) error {
+			return WriteBadge(out, entry.Stats, metric, time.Now())
 		})
 	})
 
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		// Scrapes never wait for a scan: a set is only exposed once cached.
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := writeMetrics(w, cache.metricSets(namedOpts), time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
 	mux.HandleFunc("/api/refresh", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -130,33 +370,86 @@ func Serve(opts LaunchOptions, addr string, ttl time.Duration, cacheFile string)
 // It validates the delta so a bad value is reported as a 400 rather than an
 // empty result.
 func (c *statsCache) resolve(r *http.Request) (LaunchOptions, error) {
-	if len(r.URL.Query()) == 0 {
+	return c.resolveQuery(r.URL.Query())
+}
+
+// resolveQuery builds the launch options for the query parameters of a
+// request, like resolve.
+func (c *statsCache) resolveQuery(q url.Values) (LaunchOptions, error) {
+	if len(q) == 0 {
 		return c.baseOpts, nil
 	}
-	params := parseParams(r)
+	params := parseParams(q)
 	if _, err := parseDelta(params.Delta, time.Now()); err != nil {
 		return LaunchOptions{}, err
 	}
+	if _, _, err := parseWindow(params.Since, params.Until, DefaultDurationInDays, time.Now()); err != nil {
+		return LaunchOptions{}, err
+	}
+	if params.AttributeBy != "" {
+		if _, err := ParseAttribution(params.AttributeBy); err != nil {
+			return LaunchOptions{}, err
+		}
+		if err := CheckCoAuthors(params.AttributeBy, params.CoAuthors); err != nil {
+			return LaunchOptions{}, err
+		}
+	}
+	if params.Merges != "" {
+		if _, err := ParseMerges(params.Merges); err != nil {
+			return LaunchOptions{}, err
+		}
+	}
+	if params.Timezone != "" {
+		if _, err := ParseTimezone(params.Timezone); err != nil {
+			return LaunchOptions{}, err
+		}
+	}
 	if params.Repo != "" && !containsFolder(c.baseOpts.Folders, params.Repo) {
 		return LaunchOptions{}, fmt.Errorf("unknown repository: %s", params.Repo)
 	}
 	return c.optsFor(params), nil
 }
 
-func parseParams(r *http.Request) Params {
-	q := r.URL.Query()
+func parseParams(q url.Values) Params {
 	p := Params{
 		Delta:    q.Get("delta"),
+		Since:    q.Get("since"),
+		Until:    q.Get("until"),
 		User:     q.Get("user"),
 		CountAll: isTrue(q.Get("countAll")),
 		Merge:    isTrue(q.Get("merge")),
 		Repo:     q.Get("repo"),
 		Include:  splitCSV(q.Get("include")),
 		Exclude:  splitCSV(q.Get("exclude")),
+
+		AllBranches:    isTrue(q.Get("allBranches")),
+		RemoteBranches: isTrue(q.Get("remoteBranches")),
+		Refs:           splitCSV(q.Get("refs")),
+		CoAuthors:      isTrue(q.Get("coAuthors")),
+		SurvivingLines: isTrue(q.Get("survivingLines")),
+		AttributeBy:    q.Get("attributeBy"),
+		Merges:         q.Get("merges"),
+		Timezone:       q.Get("timezone"),
+	}
+	// "series" in include asks for the weekly series, it is not a file pattern.
+	var patterns []string
+	for _, include := range p.Include {
+		if include == seriesInclude {
+			p.Series = true
+		} else {
+			patterns = append(patterns, include)
+		}
 	}
+	p.Include = patterns
 	if weeks, err := strconv.Atoi(q.Get("weeks")); err == nil {
 		p.Weeks = weeks
 	}
+	if top, err := strconv.Atoi(q.Get("topFiles")); err == nil {
+		p.TopFiles = top
+	}
+	if days, err := strconv.Atoi(q.Get("inactiveDays")); err == nil {
+		p.InactiveDays = days
+	}
 	return p
 }
 
@@ -166,9 +459,23 @@ func (c *statsCache) paramsOf(o LaunchOptions) appliedParams {
 	ap := appliedParams{
 		Weeks:   o.DurationInWeeks,
 		Delta:   o.Delta,
+		Since:   o.Since,
+		Until:   o.Until,
 		Merge:   o.Merge,
 		Include: o.PatternToInclude,
 		Exclude: o.PatternToExclude,
+
+		AllBranches:    o.AllBranches,
+		RemoteBranches: o.RemoteBranches,
+		Refs:           o.Refs,
+		CoAuthors:      o.CoAuthors,
+		AttributeBy:    o.AttributeBy,
+		Merges:         o.Merges,
+		Timezone:       o.Timezone,
+		TopFiles:       o.TopFiles,
+		InactiveDays:   o.InactiveDays,
+		SurvivingLines: o.SurvivingLines,
+		Series:         o.Series > 0,
 	}
 	if o.User == nil {
 		ap.CountAll = true
@@ -182,6 +489,58 @@ func (c *statsCache) paramsOf(o LaunchOptions) appliedParams {
 	return ap
 }
 
+// serveEntry writes the cached statistics of a set of options as a document
+// of contentType, rendered by write. The document is rendered before anything
+// is sent, so a failure is still answered with a 500.
+func (c *statsCache) serveEntry(w http.ResponseWriter, opts LaunchOptions, contentType string, write func(io.Writer, *cacheEntry) error) {
+	entry, _, _ := c.entryFor(opts)
+	if entry == nil {
+		http.Error(w, "statistics not ready", http.StatusServiceUnavailable)
+		return
+	}
+	var body bytes.Buffer
+	if err := write(&body, entry); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body.Bytes())
+}
+
+// parseCommitFilter reads the /api/commits filter: a day (date) or a range of
+// days (from, to), and a punchcard slot (weekday, Monday-first, and hour).
+func parseCommitFilter(q url.Values) (CommitFilter, error) {
+	f := CommitFilter{From: q.Get("from"), To: q.Get("to"), Weekday: -1, Hour: -1}
+	if date := q.Get("date"); date != "" {
+		f.From, f.To = date, date
+	}
+	for _, day := range []string{f.From, f.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, day); err != nil {
+			return CommitFilter{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", day)
+		}
+	}
+	slots := []struct {
+		name string
+		max  int
+		dst  *int
+	}{{"weekday", 6, &f.Weekday}, {"hour", 23, &f.Hour}}
+	for _, slot := range slots {
+		v := q.Get(slot.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > slot.max {
+			return CommitFilter{}, fmt.Errorf("invalid %s: %s", slot.name, v)
+		}
+		*slot.dst = n
+	}
+	return f, nil
+}
+
 func isTrue(v string) bool {
 	return v == "true" || v == "1" || v == "on"
 }
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Relative scan windows accepted by --since.
+const (
+	WindowYearToDate  = "ytd"          // from January 1st to today
+	WindowLastQuarter = "last-quarter" // the previous calendar quarter
+	WindowLastSprint  = "last-sprint:" // followed by a length, e.g. last-sprint:14d
+)
+
+const dateLayout = "2006-01-02"
+
+// parseWindow resolves an explicit scan window. since is a date (YYYY-MM-DD)
+// or a relative window; until is a date, and defaults to today or to the end
+// of the relative window. An empty since starts the window durationInDays
+// before until. The window starts at the beginning of its first day and ends
+// at the end of its last day.
+func parseWindow(since, until string, durationInDays int, now time.Time) (time.Time, time.Time, error) {
+	today := getBeginningOfDay(now)
+	end := getEndOfDay(today)
+	var begin time.Time
+	switch {
+	case since == "":
+		// resolved once until is known
+	case since == WindowYearToDate:
+		begin = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+	case since == WindowLastQuarter:
+		quarter := time.Date(today.Year(), (today.Month()-1)/3*3+1, 1, 0, 0, 0, 0, today.Location())
+		begin = quarter.AddDate(0, -3, 0)
+		end = getEndOfDay(quarter.AddDate(0, 0, -1))
+	case strings.HasPrefix(since, WindowLastSprint):
+		start, err := parseDelta(strings.TrimPrefix(since, WindowLastSprint), today)
+		if err != nil {
+			return begin, end, fmt.Errorf("invalid since value %q: %w", since, err)
+		}
+		begin = start.AddDate(0, 0, 1)
+	default:
+		day, err := time.ParseInLocation(dateLayout, since, now.Location())
+		if err != nil {
+			return begin, end, fmt.Errorf("invalid since value %q, use YYYY-MM-DD, %s, %s or %s<int>[y/m/w/d]", since, WindowYearToDate, WindowLastQuarter, WindowLastSprint)
+		}
+		begin = day
+	}
+
+	if until != "" {
+		day, err := time.ParseInLocation(dateLayout, until, now.Location())
+		if err != nil {
+			return begin, end, fmt.Errorf("invalid until value %q, use YYYY-MM-DD", until)
+		}
+		end = getEndOfDay(day)
+	}
+	if since == "" {
+		begin = getBeginningOfDay(end).AddDate(0, 0, 1-durationInDays)
+	}
+	if begin.After(end) {
+		return begin, end, fmt.Errorf("the scan window starts (%s) after it ends (%s)", begin.Format(dateLayout), end.Format(dateLayout))
+	}
+	return begin, end, nil
+}
+
+// dayIndex is the key of the day of t in the per-day counters (Commits and
+// DayEditions): 8 for the last day of the window, one more for each day
+// before it. Days are compared as calendar dates, t in its own time zone.
+func (r *StatsResult) dayIndex(t time.Time) int {
+	y, m, d := t.Date()
+	ey, em, ed := r.EndOfScan.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	last := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+	return int(last.Sub(day).Hours()/24) + 8
+}
+
+// windowDays is how many calendar days the window covers, its first and last
+// days included, like the calendar.
+func (r *StatsResult) windowDays() int {
+	return r.dayIndex(r.BeginOfScan) - r.dayIndex(r.EndOfScan) + 1
+}
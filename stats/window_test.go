@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	now := time.Date(2026, time.May, 20, 15, 4, 5, 0, time.UTC)
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, time.UTC)
+		return d
+	}
+	cases := []struct {
+		since, until string
+		begin, end   string
+	}{
+		{"2026-01-01", "2026-03-31", "2026-01-01", "2026-03-31"},
+		{"2026-05-01", "", "2026-05-01", "2026-05-20"},
+		{"", "2026-03-31", "2026-03-25", "2026-03-31"}, // one week before until
+		{"ytd", "", "2026-01-01", "2026-05-20"},
+		{"last-quarter", "", "2026-01-01", "2026-03-31"},
+		{"last-sprint:14d", "", "2026-05-07", "2026-05-20"},
+		{"last-sprint:2w", "2026-05-10", "2026-05-07", "2026-05-10"},
+	}
+	for _, c := range cases {
+		begin, end, err := parseWindow(c.since, c.until, 7, now)
+		if err != nil {
+			t.Errorf("parseWindow(%q, %q): %v", c.since, c.until, err)
+			continue
+		}
+		if !begin.Equal(day(c.begin)) || !end.Equal(getEndOfDay(day(c.end))) {
+			t.Errorf("parseWindow(%q, %q) = %s..%s, want %s..%s", c.since, c.until, begin, end, c.begin, c.end)
+		}
+	}
+
+	for _, c := range [][2]string{{"yesterday", ""}, {"", "2026-13-01"}, {"last-sprint:xx", ""}, {"2026-04-01", "2026-03-01"}} {
+		if _, _, err := parseWindow(c[0], c[1], 7, now); err == nil {
+			t.Errorf("parseWindow(%q, %q) should fail", c[0], c[1])
+		}
+	}
+}
+
+func TestLaunchSinceUntilIsExact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	at := func(date string, hour int) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, date, time.Local)
+		return d.Add(time.Duration(hour) * time.Hour)
+	}
+	commitFile(t, path, "a.go", "a\n", "feat: before", "alice", at("2026-02-28", 23))
+	commitFile(t, path, "b.go", "b\n", "feat: first day", "alice", at("2026-03-01", 0))
+	commitFile(t, path, "c.go", "c\n", "feat: middle", "alice", at("2026-03-18", 12))
+	commitFile(t, path, "d.go", "d\n", "feat: last day", "alice", at("2026-03-31", 23))
+	commitFile(t, path, "e.go", "e\n", "feat: after", "alice", at("2026-04-01", 0))
+
+	results := Launch(LaunchOptions{Folders: []string{path}, Dashboard: true, Since: "2026-03-01", Until: "2026-03-31"})
+	if results[0].Error != nil {
+		t.Fatal(results[0].Error)
+	}
+	agg := Aggregate(results)
+	if agg.TotalCommits != 3 {
+		t.Errorf("TotalCommits = %d, want 3", agg.TotalCommits)
+	}
+	if n := len(agg.Calendar); n != 31 || agg.Calendar[0].Date != "2026-03-01" || agg.Calendar[n-1].Date != "2026-03-31" {
+		t.Fatalf("calendar spans %d days, want March 2026", n)
+	}
+	for _, d := range agg.Calendar {
+		want := 0
+		if d.Date == "2026-03-01" || d.Date == "2026-03-18" || d.Date == "2026-03-31" {
+			want = 1
+		}
+		if d.Count != want {
+			t.Errorf("%s: %d commits, want %d", d.Date, d.Count, want)
+		}
+	}
+}
+
+func TestLaunchWindowEdgesInCommitZone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo")
+	initRepo(t, path)
+	at := func(date string, hour int) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, date, time.Local)
+		return d.Add(time.Duration(hour) * time.Hour)
+	}
+	// Inside the window, but on a day outside it in the zone of the commit.
+	first, last := at("2026-03-01", 0), at("2026-03-31", 23)
+	_, offset := first.Zone()
+	commitFile(t, path, "a.go", "a\n", "feat: a", "alice", first.In(time.FixedZone("", offset-3600)))
+	_, offset = last.Zone()
+	commitFile(t, path, "b.go", "b\n", "feat: b", "alice", last.Add(30*time.Minute).In(time.FixedZone("", offset+3600)))
+	commitFile(t, path, "c.go", "c\n", "feat: c", "alice", at("2026-03-18", 12))
+
+	agg := Aggregate(Launch(LaunchOptions{Folders: []string{path}, Dashboard: true, Since: "2026-03-01", Until: "2026-03-31"}))
+	calendar := 0
+	for _, d := range agg.Calendar {
+		calendar += d.Count
+	}
+	if agg.TotalCommits != 1 || calendar != 1 {
+		t.Errorf("%d commits, %d in the calendar, want only the middle one in both", agg.TotalCommits, calendar)
+	}
+}
+
+func TestDurationInDaysIncludesBothEnds(t *testing.T) {
+	for _, o := range []LaunchOptions{
+		{Since: "2026-01-01", Until: "2026-03-31"},
+		{DurationInWeeks: 4},
+		{},
+	} {
+		r := &StatsResult{}
+		populateDurationInDays(o, r)
+		if r.Error != nil {
+			t.Fatal(r.Error)
+		}
+		if days := len(buildCalendar(r)); r.DurationInDays != days {
+			t.Errorf("%+v: DurationInDays = %d, want the %d days of the calendar", o, r.DurationInDays, days)
+		}
+		if o.Since != "" && r.DurationInDays != 90 {
+			t.Errorf("2026-01-01..2026-03-31: DurationInDays = %d, want 90", r.DurationInDays)
+		}
+	}
+}